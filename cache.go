@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"github.com/codegangsta/cli"
+	"github.com/jlgerber/lss/pack"
+	"time"
+)
+
+var cacheCommand = cli.Command{
+	Name:  "cache",
+	Usage: "manage the listing cache.",
+	Description: `lss caches the collapsed listings of large directories, and reuses them for as
+	long as the directory's modification time is unchanged. Use --no-cache to bypass it.
+
+	lss cache prune [--max-age duration]
+	lss cache clear
+	lss cache path`,
+	Subcommands: []cli.Command{
+		{
+			Name:  "prune",
+			Usage: "remove entries for directories which have changed or vanished.",
+			Flags: []cli.Flag{
+				cli.DurationFlag{
+					Name:  "max-age",
+					Value: 30 * 24 * time.Hour,
+					Usage: "also remove entries older than this. 0 keeps entries of any age.",
+				},
+			},
+			Action: func(c *cli.Context) {
+				cache := userCache()
+				err, removed := cache.Prune(c.Duration("max-age"))
+				if err != nil {
+					fail(err)
+				}
+				fmt.Println("removed", removed, "cache entries")
+			},
+		},
+		{
+			Name:  "clear",
+			Usage: "remove every entry.",
+			Action: func(c *cli.Context) {
+				if err := userCache().Clear(); err != nil {
+					fail(err)
+				}
+			},
+		},
+		{
+			Name:  "path",
+			Usage: "print the directory holding the cache.",
+			Action: func(c *cli.Context) {
+				fmt.Println(userCache().Dir)
+			},
+		},
+	},
+}
+
+// userCache returns the user's listing cache, exiting if it cannot be located.
+func userCache() *lss.Cache {
+	dir := lss.DefaultCacheDir()
+	if dir == "" {
+		fail("cannot locate the user cache directory")
+	}
+	return lss.NewCache(dir)
+}
+
+// listingCache returns the listing cache to use, or nil if --no-cache is given or the user's
+// cache directory cannot be located.
+func listingCache(c *cli.Context) *lss.Cache {
+	dir := lss.DefaultCacheDir()
+	if flagBool(c, "no-cache") || dir == "" {
+		return nil
+	}
+	return lss.NewCache(dir)
+}
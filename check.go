@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/codegangsta/cli"
+	"github.com/jlgerber/lss/pack"
+	"os"
+	"path/filepath"
+)
+
+var checkCommand = cli.Command{
+	Name:  "check",
+	Usage: "check the sequences in a tree against the frame ranges of their shots.",
+	Description: `Walk each Path (the current directory by default) recursively, and check each
+	sequence against the frame range of its shot. A sequence belongs to the shot whose prefix
+	(its name, unless given) its own prefix starts with, the longest winning. Frames of the
+	shot's range, from cut in less handles to cut out plus handles, which a sequence lacks are
+	reported as MISSING, and frames beyond it as EXTRA, in range notation; shots without any
+	sequences are reported as EMPTY. Sequences belonging to no shot are ignored. Exits with
+	status 1 if any problems are found, and 2 if the check could not be run.
+
+	lss check --ranges <shots.json> [Path...]
+
+	The ranges file maps each shot's name to its cut_in and cut_out, which are required, and
+	its handles and prefix, which are not. It may be JSON:
+
+	    {"sh010": {"cut_in": 1001, "cut_out": 1048, "handles": 8},
+	     "sh020": {"cut_in": 1001, "cut_out": 1120, "prefix": "sh020_"}}
+
+	or YAML, written as a shot name on each unindented line, followed by its fields on indented
+	key: value lines, with # comments:
+
+	    sh010:
+	      cut_in: 1001
+	      cut_out: 1048
+	      handles: 8`,
+	Flags: append([]cli.Flag{
+		cli.StringFlag{
+			Name:  "ranges, r",
+			Usage: "the JSON or YAML file of shot cut ranges and handles.",
+		},
+	}, pickFlags(listFlags, "all", "include", "exclude", "include-regex", "exclude-regex", "ext", "no-ignore",
+		"notation", "range-sep", "list-sep", "jobs", "no-cache")...),
+	Action: func(c *cli.Context) {
+		if c.String("ranges") == "" {
+			fail("check needs a file of shot ranges (eg lss check --ranges shots.json)")
+		}
+		paths := []string(c.Args())
+		if len(paths) == 0 {
+			paths = []string{"."}
+		}
+		if err := loadSettings(paths[0]); err != nil {
+			fail(err)
+		}
+		err, shots := lss.LoadShots(c.String("ranges"))
+		if err != nil {
+			fail(err)
+		}
+		ok, problems := checkPaths(c, paths, shots)
+		switch {
+		case !ok:
+			exit(EXIT_FAILED)
+		case problems > 0:
+			exit(EXIT_PROBLEMS)
+		}
+	},
+}
+
+// checkPaths walks each of paths, reporting the sequences which do not cover the range of
+// their shot exactly, and the shots without sequences. Each path is walked with the settings
+// which apply to it. It returns false if any directory could not be read, and the number of
+// problems found.
+func checkPaths(c *cli.Context, paths []string, shots lss.Shots) (bool, int) {
+	ok, problems, checked := true, 0, 0
+	found := map[string]bool{}
+	for _, path := range paths {
+		if err := loadSettings(path); err != nil {
+			fail(err)
+		}
+		err, options := parseListOptions(c)
+		if err != nil {
+			fail(err)
+		}
+		for result := range lss.Walk(context.Background(), path, walkOptions(c)) {
+			if result.Err != nil {
+				fmt.Fprintln(os.Stderr, "lss:", result.Err)
+				ok = false
+			}
+			for i := range result.Sequences {
+				seq := &result.Sequences[i]
+				shot := shots.Match(seq.Prefix)
+				if seq.Single() || shot == nil {
+					continue
+				}
+				found[shot.Name] = true
+				checked++
+				missing, extra := shot.Check(seq)
+				pattern := filepath.Join(result.Dir, options.style.Pattern(seq))
+				if len(missing) > 0 {
+					fmt.Println("MISSING", shot.Name, pattern, options.style.Ranges(missing))
+					problems++
+				}
+				if len(extra) > 0 {
+					fmt.Println("EXTRA  ", shot.Name, pattern, options.style.Ranges(extra))
+					problems++
+				}
+			}
+		}
+	}
+	for _, shot := range shots {
+		if !found[shot.Name] {
+			fmt.Println("EMPTY  ", shot.Name, fmt.Sprintf("%d-%d", shot.First(), shot.Last()))
+			problems++
+		}
+	}
+	if problems == 0 {
+		fmt.Println("OK", checked, "sequences checked against", len(shots), "shots")
+	}
+	return ok, problems
+}
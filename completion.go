@@ -0,0 +1,236 @@
+package main
+
+import (
+	"fmt"
+	"github.com/codegangsta/cli"
+	"github.com/jlgerber/lss/pack"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// COMPLETE_COMMAND is the hidden command through which the completion scripts ask lss for
+// the completions of a partial command line.
+const COMPLETE_COMMAND = "__complete"
+
+var completionCommand = cli.Command{
+	Name:  "completion",
+	Usage: "print a completion script for bash, zsh or fish.",
+	Description: `Print a script which completes lss's commands, flags and paths in the named shell.
+	Paths complete to collapsed sequence patterns (eg beauty_v012.%04d.exr), rather than to
+	every frame, unless the frame number itself is being typed.
+
+	lss completion bash|zsh|fish
+
+	To enable completion, add to ~/.bashrc
+	    eval "$(lss completion bash)"
+	or to ~/.zshrc (after compinit)
+	    eval "$(lss completion zsh)"
+	or to ~/.config/fish/config.fish
+	    lss completion fish | source`,
+	Action: func(c *cli.Context) {
+		script, ok := completionScripts[c.Args().First()]
+		if !ok {
+			fail("completion needs a shell: bash, zsh or fish")
+		}
+		fmt.Print(script)
+	},
+}
+
+// completionScripts holds the completion script of each shell. Each hands the words of the
+// command line, up to and including the one being completed, to lss __complete.
+var completionScripts = map[string]string{
+	"bash": `_lss_complete() {
+    local IFS=$'\n'
+    mapfile -t COMPREPLY < <(lss ` + COMPLETE_COMMAND + ` "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null)
+    if [[ ${#COMPREPLY[@]} -eq 1 && ${COMPREPLY[0]} == */ ]]; then
+        compopt -o nospace
+    fi
+}
+complete -o filenames -F _lss_complete lss
+`,
+	"zsh": `_lss_complete() {
+    local -a completions
+    completions=(${(f)"$(lss ` + COMPLETE_COMMAND + ` "${(@)words[2,CURRENT]}" 2>/dev/null)"})
+    compadd -f -S '' -- ${(M)completions:#*/}
+    compadd -f -- ${completions:#*/}
+}
+compdef _lss_complete lss
+`,
+	"fish": `function __lss_complete
+    set -l words (commandline -opc)
+    set -l current (commandline -ct)
+    lss ` + COMPLETE_COMMAND + ` $words[2..-1] "$current" 2>/dev/null
+end
+complete -c lss -f -a '(__lss_complete)'
+`,
+}
+
+// flagChoices lists the values of the flags which take one of a few.
+var flagChoices = map[string][]string{
+	"sort":           {"name", "size", "time", "count", "start", "end"},
+	"padding-policy": {"auto", "prefer-padded", "prefer-unpadded", "split"},
+	"color":          {"auto", "always", "never"},
+	"notation":       {"printf", "nuke", "hash", "maya", "at", "houdini", "rv"},
+	"log-level":      {"debug", "info", "warn", "error", "off"},
+	"fps":            {"23.976", "24", "25", "29.97", "29.97ndf", "30", "48", "50", "59.94", "60"},
+}
+
+// complete prints the completions of the last of words, which are the words of a partial
+// command line following lss itself, one to a line: the commands, flags, flag values or
+// paths which may follow the words before it.
+func complete(app *cli.App, words []string) {
+	word := ""
+	if len(words) > 0 {
+		word, words = words[len(words)-1], words[:len(words)-1]
+	}
+
+	var command *cli.Command
+	flags := app.Flags
+	subcommands := []string{}
+	values := map[string]string{} // the values given to flags, by name
+	args := 0                     // the arguments given to the command
+	for i := 0; i < len(words); i++ {
+		w := words[i]
+		if strings.HasPrefix(w, "-") {
+			name, value, hasValue := strings.Cut(strings.TrimLeft(w, "-"), "=")
+			if !hasValue && flagTakesValue(flags, name) && i+1 < len(words) {
+				i++
+				value = words[i]
+			}
+			values[longFlagName(flags, name)] = value
+			continue
+		}
+		switch {
+		case command == nil && args == 0 && app.Command(w) != nil:
+			command = app.Command(w)
+			flags = command.Flags
+			for _, sub := range command.Subcommands {
+				subcommands = append(subcommands, sub.Name)
+			}
+		case command != nil && len(subcommands) > 0 && args == 0:
+			for _, sub := range command.Subcommands {
+				if sub.HasName(w) {
+					flags = sub.Flags
+				}
+			}
+			subcommands = nil
+			args++
+		default:
+			args++
+		}
+	}
+
+	completions := []string{}
+	if len(words) > 0 && strings.HasPrefix(words[len(words)-1], "-") && !strings.Contains(words[len(words)-1], "=") {
+		// complete the value of the preceding flag, if it takes one of a few
+		if name := strings.TrimLeft(words[len(words)-1], "-"); flagTakesValue(flags, name) {
+			printCompletions(word, flagChoices[longFlagName(flags, name)])
+			return
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(word, "-"):
+		for _, f := range flags {
+			names, _ := flagNames(f)
+			completions = append(completions, "--"+names[0])
+		}
+	case command == nil && args == 0:
+		for _, cmd := range app.Commands {
+			completions = append(completions, cmd.Name)
+		}
+		completions = append(completions, completePath(word, values)...)
+	case command != nil && len(subcommands) > 0:
+		completions = subcommands
+	case command != nil && command.Name == "completion":
+		for shell := range completionScripts {
+			completions = append(completions, shell)
+		}
+	default:
+		completions = completePath(word, values)
+	}
+	printCompletions(word, completions)
+}
+
+// completePath returns the completions of word as a path, with patterns written in the
+// notation given on the command line, or else the configured one.
+func completePath(word string, values map[string]string) []string {
+	loadSettings(filepath.Dir(word))
+	notation, ok := values["notation"]
+	if !ok {
+		notation = settings.Defaults["notation"]
+	}
+	_, style := lss.ParseNotation(notation)
+	err, completions := lss.CompletePath(word, lss.Style{Notation: style})
+	if err != nil {
+		return nil
+	}
+	return completions
+}
+
+// printCompletions prints those completions which begin with word, in order.
+func printCompletions(word string, completions []string) {
+	sort.Strings(completions)
+	for _, completion := range completions {
+		if strings.HasPrefix(completion, word) {
+			fmt.Println(completion)
+		}
+	}
+}
+
+// flagNames returns the names of f, its long name first, and whether it takes a value.
+func flagNames(f cli.Flag) ([]string, bool) {
+	var name string
+	takesValue := true
+	switch f := f.(type) {
+	case cli.BoolFlag:
+		name, takesValue = f.Name, false
+	case cli.BoolTFlag:
+		name, takesValue = f.Name, false
+	case cli.StringFlag:
+		name = f.Name
+	case cli.StringSliceFlag:
+		name = f.Name
+	case cli.IntFlag:
+		name = f.Name
+	case cli.DurationFlag:
+		name = f.Name
+	default:
+		name = f.String()
+	}
+	names := []string{}
+	for _, n := range strings.Split(name, ",") {
+		names = append(names, strings.TrimSpace(n))
+	}
+	return names, takesValue
+}
+
+// findFlag returns the names of the flag called name among flags, and whether it takes a
+// value, or nil if there is no such flag.
+func findFlag(flags []cli.Flag, name string) ([]string, bool) {
+	for _, f := range flags {
+		names, takesValue := flagNames(f)
+		for _, n := range names {
+			if n == name {
+				return names, takesValue
+			}
+		}
+	}
+	return nil, false
+}
+
+// flagTakesValue returns true if the flag called name among flags takes a value.
+func flagTakesValue(flags []cli.Flag, name string) bool {
+	_, takesValue := findFlag(flags, name)
+	return takesValue
+}
+
+// longFlagName returns the long name of the flag called name among flags, or name itself if
+// there is no such flag.
+func longFlagName(flags []cli.Flag, name string) string {
+	if names, _ := findFlag(flags, name); names != nil {
+		return names[0]
+	}
+	return name
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"github.com/codegangsta/cli"
+	"github.com/jlgerber/lss/pack"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// settings holds the defaults resolved from the settings files and environment by
+// loadSettings.
+var settings = lss.NewConfig()
+
+var configCommand = cli.Command{
+	Name:  "config",
+	Usage: "show the settings in effect.",
+	Description: `Defaults for lss's flags are read from ~/.config/lss/config, from .lssrc files in
+	the listed directory and its ancestors, and from LSS_* environment variables (eg LSS_SORT),
+	each overriding the last. Flags given on the command line override them all.
+
+	lss config show [directory]`,
+	Subcommands: []cli.Command{
+		{
+			Name:  "show",
+			Usage: "print each setting, along with where its value came from.",
+			Action: func(c *cli.Context) {
+				dir := "."
+				if c.Args().Present() {
+					dir = c.Args().First()
+				}
+				if err := loadSettings(dir); err != nil {
+					fail(err)
+				}
+				showSettings(c, dir)
+			},
+		},
+	},
+}
+
+// loadSettings resolves the settings which apply to path, or to the directory holding it if
+// it is a file.
+func loadSettings(path string) error {
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		path = filepath.Dir(path)
+	}
+	err, loaded := lss.LoadConfigs(path, os.LookupEnv)
+	if err != nil {
+		return err
+	}
+	settings = loaded
+	return nil
+}
+
+// settingString returns the value of the string flag name, as given on the command line, or
+// else its configured default, or else the flag's own default.
+func settingString(c *cli.Context, name string) string {
+	if value, ok := settings.Defaults[name]; ok && !flagIsSet(c, name) {
+		return value
+	}
+	return flagString(c, name)
+}
+
+// settingBool returns the value of the switch name, as settingString does.
+func settingBool(c *cli.Context, name string) bool {
+	if value, ok := settings.Bool(name); ok && !flagIsSet(c, name) {
+		return value
+	}
+	return flagBool(c, name)
+}
+
+// settingList returns the value of the list flag name, as settingString does.
+func settingList(c *cli.Context, name string) []string {
+	if value, ok := settings.Defaults[name]; ok && !flagIsSet(c, name) {
+		return lss.SplitList(value)
+	}
+	return flagStringSlice(c, name)
+}
+
+// flagIsSet returns true if the flag name was given, either to the command or before it.
+// Listing flags may be given in either place (eg lss -a list, or lss list -a), and those
+// given to the command win.
+func flagIsSet(c *cli.Context, name string) bool {
+	return c.IsSet(name) || c.GlobalIsSet(name)
+}
+
+// flagString returns the value of the string flag name, wherever it was given.
+func flagString(c *cli.Context, name string) string {
+	if c.IsSet(name) {
+		return c.String(name)
+	}
+	return c.GlobalString(name)
+}
+
+// flagBool returns the value of the switch name, wherever it was given.
+func flagBool(c *cli.Context, name string) bool {
+	if c.IsSet(name) {
+		return c.Bool(name)
+	}
+	return c.GlobalBool(name)
+}
+
+// flagInt returns the value of the int flag name, wherever it was given.
+func flagInt(c *cli.Context, name string) int {
+	if c.IsSet(name) {
+		return c.Int(name)
+	}
+	return c.GlobalInt(name)
+}
+
+// flagDuration returns the value of the duration flag name, wherever it was given.
+func flagDuration(c *cli.Context, name string) time.Duration {
+	if c.IsSet(name) {
+		return c.Duration(name)
+	}
+	return c.GlobalDuration(name)
+}
+
+// flagStringSlice returns the value of the list flag name, wherever it was given.
+func flagStringSlice(c *cli.Context, name string) []string {
+	if c.IsSet(name) {
+		return c.StringSlice(name)
+	}
+	return c.GlobalStringSlice(name)
+}
+
+// showSettings prints the value of each setting in effect for dir, and where it came from,
+// followed by the named templates.
+func showSettings(c *cli.Context, dir string) {
+	abs, _ := filepath.Abs(dir)
+	fmt.Println("# settings for", abs)
+	if len(settings.Files) == 0 {
+		fmt.Println("# no settings files found")
+	}
+	for _, file := range settings.Files {
+		fmt.Println("# read", file)
+	}
+
+	fmt.Println("[defaults]")
+	for _, setting := range lss.SETTINGS {
+		var value string
+		switch setting.Kind {
+		case lss.SETTING_BOOL:
+			value = strconv.FormatBool(settingBool(c, setting.Name))
+		case lss.SETTING_LIST:
+			value = strings.Join(settingList(c, setting.Name), ", ")
+		default:
+			value = settingString(c, setting.Name)
+		}
+		source := "default"
+		switch {
+		case flagIsSet(c, setting.Name):
+			source = "command line"
+		case settings.Sources[setting.Name] != "":
+			source = settings.Sources[setting.Name]
+		}
+		if value != strings.TrimSpace(value) {
+			value = strconv.Quote(value)
+		}
+		fmt.Printf("%-15s = %-20s # %s\n", setting.Name, value, source)
+	}
+
+	if len(settings.Templates) > 0 {
+		fmt.Println("[templates]")
+		names := []string{}
+		for name := range settings.Templates {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("%-15s = %s\n", name, settings.Templates[name])
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// Exit statuses, as documented in the help. Scripts may rely upon them.
+const (
+	EXIT_OK       = 0 // success
+	EXIT_PROBLEMS = 1 // ran, and found problems (eg verify found changed or missing files)
+	EXIT_FAILED   = 2 // could not run (eg a bad flag, or an unreadable directory)
+)
+
+// fail reports err and exits with EXIT_FAILED.
+func fail(err interface{}) {
+	fmt.Fprintln(os.Stderr, "lss:", err)
+	exit(EXIT_FAILED)
+}
+
+// exit closes the log file, which os.Exit would leave unflushed, and exits with status.
+func exit(status int) {
+	closeLogFile(nil)
+	os.Exit(status)
+}
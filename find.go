@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/codegangsta/cli"
+	"github.com/jlgerber/lss/pack"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var findCommand = cli.Command{
+	Name:  "find",
+	Usage: "find the sequences in a tree matching some criteria.",
+	Description: `Walk each Path (the current directory by default) recursively, and print the
+	collapsed sequences matching every criterion given, with their directories. Numbers are
+	given as in find(1): +N for more than N, -N for fewer than N, and N for exactly N. Sizes
+	may carry a unit of K, M, G or T, and ages one of s, m, h, d or w (days by default).
+
+	lss find [options] [Path...]
+
+	For example, the EXR sequences with fewer than 100 frames, or those modified within the
+	last day:
+
+	    lss find --ext exr --count -100 shots/sh010
+	    lss find --mtime -1d --format json shots`,
+	Flags: append([]cli.Flag{
+		cli.StringFlag{
+			Name:  "prefix, p",
+			Usage: "only find sequences whose prefix matches this glob (eg 'beauty_*').",
+		},
+		cli.StringFlag{
+			Name:  "padding",
+			Usage: "only find sequences padded to this width (1 for unpadded).",
+		},
+		cli.StringFlag{
+			Name:  "count",
+			Usage: "only find sequences with this many frames (eg -100).",
+		},
+		cli.StringFlag{
+			Name:  "first",
+			Usage: "only find sequences starting at this frame (eg +1000).",
+		},
+		cli.StringFlag{
+			Name:  "last",
+			Usage: "only find sequences ending at this frame.",
+		},
+		cli.BoolFlag{
+			Name:  "gaps",
+			Usage: "only find sequences with missing frames.",
+		},
+		cli.BoolFlag{
+			Name:  "no-gaps",
+			Usage: "only find sequences without missing frames.",
+		},
+		cli.StringFlag{
+			Name:  "size",
+			Usage: "only find sequences totalling this size (eg +1G).",
+		},
+		cli.StringFlag{
+			Name:  "mtime",
+			Usage: "only find sequences last modified this long ago (eg -1d, within the last day).",
+		},
+		cli.BoolFlag{
+			Name:  "singles",
+			Usage: "find single files as well as sequences.",
+		},
+		cli.StringFlag{
+			Name:  "format, f",
+			Value: "text",
+			Usage: "output format: text or json.",
+		},
+	}, pickFlags(listFlags, "all", "include", "exclude", "include-regex", "exclude-regex", "ext", "no-ignore",
+		"sort", "reverse", "notation", "range-sep", "list-sep", "color", "fps", "template", "jobs", "no-cache")...),
+	Action: func(c *cli.Context) {
+		paths := []string(c.Args())
+		if len(paths) == 0 {
+			paths = []string{"."}
+		}
+		if err := loadSettings(paths[0]); err != nil {
+			fail(err)
+		}
+		err, query := parseFindQuery(c)
+		if err != nil {
+			fail(err)
+		}
+		format := c.String("format")
+		if format != "text" && format != "json" {
+			fail("unknown find format: " + format)
+		}
+		ok, problems := findPaths(c, paths, query, format)
+		switch {
+		case !ok:
+			exit(EXIT_FAILED)
+		case problems:
+			exit(EXIT_PROBLEMS)
+		}
+	},
+}
+
+// parseFindQuery gathers the criteria given to lss find.
+func parseFindQuery(c *cli.Context) (error, lss.FindQuery) {
+	query := lss.FindQuery{Prefix: c.String("prefix"), Singles: c.Bool("singles")}
+	switch {
+	case c.Bool("gaps") && c.Bool("no-gaps"):
+		return fmt.Errorf("--gaps and --no-gaps are mutually exclusive"), query
+	case c.Bool("gaps"):
+		query.Gaps = lss.GAPS_ONLY
+	case c.Bool("no-gaps"):
+		query.Gaps = lss.GAPS_NONE
+	}
+	comparisons := []struct {
+		name  string
+		units map[string]int64
+		cmp   *lss.Comparison
+	}{
+		{"padding", nil, &query.Padding},
+		{"count", nil, &query.Count},
+		{"first", nil, &query.First},
+		{"last", nil, &query.Last},
+		{"size", lss.SIZE_UNITS, &query.Size},
+		{"mtime", lss.AGE_UNITS, &query.Age},
+	}
+	for _, comparison := range comparisons {
+		if value := c.String(comparison.name); value != "" {
+			err, cmp := lss.ParseComparison(value, comparison.units)
+			if err != nil {
+				return fmt.Errorf("--%s: %v", comparison.name, err), query
+			}
+			*comparison.cmp = cmp
+		}
+	}
+	return query.Check(), query
+}
+
+// findPaths walks each of paths, printing the sequences which match query as text or JSON.
+// Each path is walked with the settings which apply to it. Directories which cannot be read,
+// and paths whose settings are bad, are reported and skipped, so that the output is always
+// complete (and JSON output valid). It returns false if any directory could not be read, and
+// true as its second value if any path was skipped for its settings.
+func findPaths(c *cli.Context, paths []string, query lss.FindQuery, format string) (bool, bool) {
+	ok, problems := true, false
+	now := time.Now()
+
+	records := 0
+	if format == "json" {
+		fmt.Print("[")
+	}
+	for _, path := range paths {
+		err, options, rate := findSettings(c, path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "lss:", path+":", err)
+			problems = true
+			continue
+		}
+		for result := range lss.Walk(context.Background(), path, walkOptions(c)) {
+			if result.Err != nil {
+				fmt.Fprintln(os.Stderr, "lss:", result.Err)
+				ok = false
+			}
+			found := []lss.Sequence{}
+			for i := range result.Sequences {
+				seq := &result.Sequences[i]
+				err, matched, stat := query.Matches(result.Dir, seq, now)
+				if err != nil {
+					fmt.Fprintln(os.Stderr, "lss:", err)
+					ok = false
+				}
+				if !matched {
+					continue
+				}
+				if format == "text" {
+					// carry the directory in the prefix, as files named on the command line do
+					joined := *seq
+					joined.Prefix = filepath.Join(result.Dir, seq.Prefix)
+					found = append(found, joined)
+					continue
+				}
+				if stat == nil {
+					stat = &lss.SequenceStat{}
+					if err, *stat = lss.StatSequence(result.Dir, seq); err != nil {
+						fmt.Fprintln(os.Stderr, "lss:", err)
+						ok = false
+					}
+				}
+				data, _ := json.MarshalIndent(lss.NewSequenceRecord(result.Dir, seq, options.style, *stat, rate), "  ", "  ")
+				if records > 0 {
+					fmt.Print(",")
+				}
+				fmt.Print("\n  ", string(data))
+				records++
+			}
+			if len(found) > 0 && !printSequences(c, "", found, options) {
+				ok = false
+			}
+		}
+	}
+	if format == "json" {
+		fmt.Println("\n]")
+	}
+	return ok, problems
+}
+
+// findSettings loads the settings which apply to path, returning the listing options and
+// frame rate they give.
+func findSettings(c *cli.Context, path string) (error, listOptions, lss.Rate) {
+	if err := loadSettings(path); err != nil {
+		return err, listOptions{}, lss.Rate{}
+	}
+	err, options := parseListOptions(c)
+	if err != nil {
+		return err, options, lss.Rate{}
+	}
+	err, rate := settingRate(c)
+	return err, options, rate
+}
+
+// pickFlags returns those of flags with the given long names, in the order named, so that
+// commands may share flags with the listing.
+func pickFlags(flags []cli.Flag, names ...string) []cli.Flag {
+	picked := []cli.Flag{}
+	for _, name := range names {
+		for _, f := range flags {
+			if fnames, _ := flagNames(f); fnames[0] == name {
+				picked = append(picked, f)
+			}
+		}
+	}
+	return picked
+}
@@ -0,0 +1,428 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/codegangsta/cli"
+	"github.com/jlgerber/lss/pack"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// listFlags are the flags which shape listings. They are accepted both before any command,
+// for the default listing, and by the list command itself.
+var listFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  "all, a",
+		Usage: "show hidden files.",
+	},
+	cli.StringSliceFlag{
+		Name:  "include, i",
+		Value: &cli.StringSlice{},
+		Usage: "only list names matching this glob. May be repeated.",
+	},
+	cli.StringSliceFlag{
+		Name:  "exclude, x",
+		Value: &cli.StringSlice{},
+		Usage: "do not list names matching this glob. May be repeated.",
+	},
+	cli.StringSliceFlag{
+		Name:  "include-regex",
+		Value: &cli.StringSlice{},
+		Usage: "only list names matching this regular expression. May be repeated.",
+	},
+	cli.StringSliceFlag{
+		Name:  "exclude-regex",
+		Value: &cli.StringSlice{},
+		Usage: "do not list names matching this regular expression. May be repeated.",
+	},
+	cli.StringSliceFlag{
+		Name:  "ext, e",
+		Value: &cli.StringSlice{},
+		Usage: "only list names with these extensions (eg exr,dpx).",
+	},
+	cli.BoolFlag{
+		Name:  "only-sequences",
+		Usage: "only list numbered files.",
+	},
+	cli.BoolFlag{
+		Name:  "only-singles",
+		Usage: "only list files which are not part of a sequence.",
+	},
+	cli.BoolFlag{
+		Name:  "no-ignore",
+		Usage: "do not apply .lssignore files.",
+	},
+	cli.StringFlag{
+		Name:  "sort, s",
+		Value: "name",
+		Usage: "sort sequences by name, size, time, count, start or end.",
+	},
+	cli.BoolFlag{
+		Name:  "reverse, r",
+		Usage: "reverse the sort order.",
+	},
+	cli.StringFlag{
+		Name:  "padding-policy",
+		Value: "auto",
+		Usage: "place ambiguous frames (eg foo.100.exr) by rule (auto), or prefer-padded, prefer-unpadded or split.",
+	},
+	cli.BoolFlag{
+		Name:  "explain",
+		Usage: "after each listing, explain how ambiguous frames were placed.",
+	},
+	cli.StringFlag{
+		Name:  "color",
+		Value: "auto",
+		Usage: "color names by kind (see LSS_COLORS): auto (when writing to a terminal), always or never.",
+	},
+	cli.StringFlag{
+		Name:  "notation",
+		Value: "printf",
+		Usage: "write patterns as printf (foo.%04d.exr), hash (foo.####.exr), at (foo.@@@@.exr), houdini (foo.$F4.exr) or rv (foo.1-100#.exr).",
+	},
+	cli.StringFlag{
+		Name:  "range-sep",
+		Value: "-",
+		Usage: "separate the first and last frames of a range with this.",
+	},
+	cli.StringFlag{
+		Name:  "list-sep",
+		Value: ",",
+		Usage: "separate ranges of frames with this.",
+	},
+	cli.StringFlag{
+		Name:  "template, t",
+		Usage: "print each sequence through this Go template (eg '{{.Pattern}} {{.First}}-{{.Last}}'), or one named in the config file.",
+	},
+	cli.StringFlag{
+		Name:  "fps",
+		Usage: "add the timecodes of the first and last frames, and the duration, at this rate (eg 24, 23.976, 25 or 29.97).",
+	},
+	cli.BoolFlag{
+		Name:  "columns, C",
+		Usage: "list entries in columns across the width of the terminal.",
+	},
+	cli.BoolFlag{
+		Name:  "recursive, R",
+		Usage: "list subdirectories recursively.",
+	},
+	cli.IntFlag{
+		Name:  "jobs, j",
+		Value: 8,
+		Usage: "number of directories to read concurrently with --recursive.",
+	},
+	cli.BoolFlag{
+		Name:  "no-cache",
+		Usage: "do not use or update the listing cache.",
+	},
+	cli.BoolFlag{
+		Name:  "watch, w",
+		Usage: "re-list the directory every --interval, showing what changed.",
+	},
+	cli.DurationFlag{
+		Name:  "interval, n",
+		Value: 2 * time.Second,
+		Usage: "how often --watch re-lists the directory (eg 500ms, 5s or 1m).",
+	},
+	cli.IntFlag{
+		Name:  "expect",
+		Usage: "number of frames each sequence should end up with, for --watch estimates.",
+	},
+}
+
+var listCommand = cli.Command{
+	Name:      "list",
+	ShortName: "ls",
+	Usage:     "list directories, collapsing sequences. This is what lss does with no command.",
+	Description: `List each Path (the current directory by default) with sequences of files collapsed
+	to single lines. Files named directly are collapsed together, before any directories.
+
+	lss [options] [Path...]
+	lss list [options] [Path...]`,
+	Flags:  listFlags,
+	Action: listAction,
+}
+
+// listAction lists the paths given as arguments, or the current directory, or watches the
+// first of them with --watch.
+func listAction(c *cli.Context) {
+	paths := []string(c.Args())
+	if len(paths) == 0 {
+		paths = []string{lss.GetCwdPath()}
+	}
+	slog.Debug("listing", "paths", paths)
+	if err := loadSettings(paths[0]); err != nil {
+		fail(err)
+	}
+
+	if flagBool(c, "watch") {
+		path := paths[0]
+		err, filter := listingFilter(c, path)
+		if err != nil {
+			fail(err)
+		}
+		err, options := parseListOptions(c)
+		if err != nil {
+			fail(err)
+		}
+		err, format := outputFormatter(c, path, options.style)
+		if err != nil {
+			fail(err)
+		}
+		watchPath(path, filter, options.policy, listingCache(c), format, flagDuration(c, "interval"), flagInt(c, "expect"))
+		return
+	}
+
+	if !listPaths(c, paths) {
+		exit(EXIT_FAILED)
+	}
+}
+
+// listOptions holds the parsed flags which shape each listing.
+type listOptions struct {
+	key      lss.SortKey
+	policy   lss.PaddingPolicy
+	style    lss.Style
+	template *lss.Template // renders each sequence, or nil for the usual listing
+}
+
+// parseListOptions parses the flags, and their configured defaults, which shape each listing.
+func parseListOptions(c *cli.Context) (error, listOptions) {
+	options := listOptions{}
+	err, key := lss.ParseSortKey(settingString(c, "sort"))
+	if err != nil {
+		return err, options
+	}
+	options.key = key
+	err, policy := lss.ParsePaddingPolicy(settingString(c, "padding-policy"))
+	if err != nil {
+		return err, options
+	}
+	options.policy = policy
+	err, notation := lss.ParseNotation(settingString(c, "notation"))
+	if err != nil {
+		return err, options
+	}
+	options.style = lss.Style{
+		Notation: notation,
+		RangeSep: settingString(c, "range-sep"),
+		ListSep:  settingString(c, "list-sep"),
+	}
+	if name := settingString(c, "template"); name != "" {
+		err, tmpl := settings.Template(name)
+		if err != nil {
+			return err, options
+		}
+		options.template = tmpl
+	}
+	return nil, options
+}
+
+// listPaths lists each of the supplied paths in the manner of ls: files named directly are
+// collapsed together first, followed by each directory under a header. Headers are only
+// printed when more than one path is supplied. It returns false if any path could not be
+// listed.
+func listPaths(c *cli.Context, paths []string) bool {
+	ok := true
+	err, options := parseListOptions(c)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "lss:", err)
+		return false
+	}
+	errs, dirs, files := lss.ClassifyPaths(paths)
+	for _, err := range errs {
+		fmt.Fprintln(os.Stderr, "lss:", err)
+		ok = false
+	}
+
+	printed := false
+	if len(files) > 0 {
+		// files named explicitly are listed even if hidden or ignored
+		filters := filterOptions(c)
+		filters.ShowHidden = true
+		err, filter := filters.Filter()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "lss:", err)
+			return false
+		}
+
+		names := []string{}
+		for _, group := range files {
+			for _, name := range group.Names {
+				if !filter.Keep(name) {
+					continue
+				}
+				if group.Dir != "." {
+					name = filepath.Join(group.Dir, name)
+				}
+				names = append(names, name)
+			}
+		}
+		// names carry their directories, so stat relative to the working directory
+		if !printSequences(c, "", lss.SequencesFromStringSlice(names), options) {
+			ok = false
+		}
+		printed = len(names) > 0
+	}
+
+	for _, dir := range dirs {
+		if printed {
+			fmt.Println()
+		}
+		printed = true
+		// each directory is listed with the settings which apply to it
+		if err := loadSettings(dir); err != nil {
+			fmt.Fprintln(os.Stderr, "lss:", err)
+			ok = false
+			continue
+		}
+		if err, options = parseListOptions(c); err != nil {
+			fmt.Fprintln(os.Stderr, "lss:", err)
+			ok = false
+			continue
+		}
+		if flagBool(c, "recursive") {
+			if !walkDirectory(c, dir, options) {
+				ok = false
+			}
+			continue
+		}
+		if len(paths) > 1 {
+			fmt.Println(dir + ":")
+		}
+		if !listDirectory(c, dir, options) {
+			ok = false
+		}
+	}
+	return ok
+}
+
+// listDirectory prints the collapsed contents of a single directory.
+func listDirectory(c *cli.Context, path string, options listOptions) bool {
+	err, filter := listingFilter(c, path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "lss:", err)
+		return false
+	}
+
+	err, seqs := lss.CachedCollapsePath(listingCache(c), path, filter)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "lss:", err)
+		return false
+	}
+	return printSequences(c, path, seqs, options)
+}
+
+// walkDirectory prints the collapsed contents of root and each of its subdirectories, in the
+// manner of ls -R. Directories which cannot be read are reported and skipped.
+func walkDirectory(c *cli.Context, root string, options listOptions) bool {
+	ok := true
+	first := true
+	for result := range lss.Walk(context.Background(), root, walkOptions(c)) {
+		if !first {
+			fmt.Println()
+		}
+		first = false
+		fmt.Println(result.Dir + ":")
+		if result.Err != nil {
+			fmt.Fprintln(os.Stderr, "lss:", result.Err)
+			ok = false
+		}
+		if !printSequences(c, result.Dir, result.Sequences, options) {
+			ok = false
+		}
+	}
+	return ok
+}
+
+// walkOptions gathers the options of a walk from the flags, and the settings in effect.
+func walkOptions(c *cli.Context) lss.WalkOptions {
+	return lss.WalkOptions{
+		Jobs: flagInt(c, "jobs"),
+		Filter: func(dir string) (error, lss.Filter) {
+			return listingFilter(c, dir)
+		},
+		Descend: func(dir string) (error, lss.Filter) {
+			return descendFilter(c, dir)
+		},
+		Cache: listingCache(c),
+	}
+}
+
+// printSequences places the ambiguous frames of the Sequences found in dir ("" if their
+// names carry their directories) as options ask, sorts them, and prints them, through the
+// template if there is one, and otherwise as --color and --columns ask. With --explain, the
+// placement of the ambiguous frames is explained afterwards.
+func printSequences(c *cli.Context, dir string, seqs []lss.Sequence, options listOptions) bool {
+	explain := flagBool(c, "explain")
+	decisions := []lss.PaddingDecision{}
+	if explain || options.policy != lss.POLICY_AUTO {
+		seqs, decisions = lss.RegroupSequences(seqs, nil, options.policy)
+	}
+	if err := lss.SortSequences(dir, seqs, options.key, settingBool(c, "reverse")); err != nil {
+		fmt.Fprintln(os.Stderr, "lss:", err)
+		return false
+	}
+
+	if options.template != nil {
+		for i := range seqs {
+			if err := options.template.Execute(os.Stdout, lss.NewSequenceData(dir, &seqs[i], options.style)); err != nil {
+				fmt.Fprintln(os.Stderr, "lss:", err)
+				return false
+			}
+		}
+	} else {
+		err, format := outputFormatter(c, dir, options.style)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "lss:", err)
+			return false
+		}
+		for _, line := range formatSequences(c, format, seqs) {
+			fmt.Println(line)
+		}
+	}
+	if explain {
+		printDecisions(decisions)
+	}
+	return true
+}
+
+// printDecisions explains where each group of ambiguous frames was placed, and why.
+func printDecisions(decisions []lss.PaddingDecision) {
+	if len(decisions) == 0 {
+		return
+	}
+	fmt.Println()
+	fmt.Println("Ambiguous padding:")
+	for _, decision := range decisions {
+		names := decision.Items.Names()
+		if len(names) > 3 {
+			names = []string{names[0], "...", names[len(names)-1]}
+		}
+		fmt.Printf("  %s (%s)\n", strings.Join(names, " "), plural(len(decision.Items.Frames), "frame"))
+		fmt.Println("    could join:", strings.Join(decision.Candidates, " or "))
+		if decision.Chosen == "" {
+			fmt.Println("    kept apart:", decision.Apart)
+		} else {
+			fmt.Println("    joined:    ", decision.Chosen)
+		}
+		if decision.Rule > 0 {
+			fmt.Printf("    rule %d:     %s\n", decision.Rule, decision.Reason)
+		} else {
+			fmt.Println("    because:   ", decision.Reason)
+		}
+	}
+}
+
+// plural returns n followed by noun, pluralised as needed.
+func plural(n int, noun string) string {
+	if n != 1 {
+		noun += "s"
+	}
+	return strconv.Itoa(n) + " " + noun
+}
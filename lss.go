@@ -1,9 +1,10 @@
 package main
 
 import (
-	"fmt"
+	"errors"
 	"github.com/codegangsta/cli"
 	"github.com/jlgerber/lss/pack"
+	"log/slog"
 	"os"
 )
 
@@ -14,51 +15,136 @@ func main() {
 	app.Name = "lss"
 	app.Usage = lss.Usage
 
-	app.Flags = []cli.Flag{
+	app.Flags = append([]cli.Flag{
 		cli.BoolFlag{
-			Name: "debug, d",
-			//Value: false,
-			Usage: "turn debugging on.",
+			Name:  "debug, d",
+			Usage: "log how each file is classified and grouped (same as --log-level debug).",
 		},
-		cli.BoolFlag{
-			Name:  "all, a",
-			Usage: "show hidden files.",
+		cli.StringFlag{
+			Name:  "log-level",
+			Value: "off",
+			Usage: "log at this level or above: debug, info, warn, error or off.",
+		},
+		cli.StringFlag{
+			Name:  "log-file",
+			Usage: "write the log to this file rather than stderr.",
 		},
+	}, listFlags...)
+	app.Action = listAction
+
+	app.Before = setupLogging
+	app.After = closeLogFile
+
+	app.Commands = []cli.Command{
+		listCommand,
+		findCommand,
+		expandCommand,
+		genCommand,
+		convertCommand,
+		manifestCommand,
+		verifyCommand,
+		checkCommand,
+		cacheCommand,
+		configCommand,
+		completionCommand,
 	}
 
-	app.Action = func(c *cli.Context) {
-		args := c.Args()
-		path := lss.GetCwdPath()
-		if len(args) > 0 {
-			path = args[0]
-		}
+	if len(os.Args) > 1 && os.Args[1] == COMPLETE_COMMAND {
+		complete(app, os.Args[2:])
+		return
+	}
+
+	if err := app.Run(os.Args); err != nil {
+		fail(err)
+	}
+}
 
-		debug := c.Bool("debug")
+// logFile is the file opened for --log-file, if any.
+var logFile *os.File
 
-		if debug {
-			println("Path:", path)
-			println("-----------")
-		}
+// setupLogging directs the log output of lss, and of the pack library, as requested by
+// --debug, --log-level and --log-file.
+func setupLogging(c *cli.Context) error {
+	level := c.GlobalString("log-level")
+	if c.GlobalBool("debug") {
+		level = "debug"
+	}
+	if level == "off" {
+		lss.SetLogger(nil)
+		return nil
+	}
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return errors.New("unknown log level '" + level + "' (use debug, info, warn, error or off)")
+	}
 
-		showHidden := func(nm string) bool {
-			if c.Bool("all") == true {
-				return true
-			}
-			if string(nm[0]) == "." {
-				return false
-			}
-			return true
-		}
-		// unsorted path contents
-		err, contents := lss.FilteredListingFromPath(path, showHidden)
+	out := os.Stderr
+	if path := c.GlobalString("log-file"); path != "" {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 		if err != nil {
-			fmt.Println(err)
-		} else {
-			for value := range lss.RangesChanFromStringSlice(contents) {
-				fmt.Println(value)
-			}
+			return err
 		}
+		out, logFile = f, f
 	}
+	logger := slog.New(slog.NewTextHandler(out, &slog.HandlerOptions{Level: lvl}))
+	slog.SetDefault(logger)
+	lss.SetLogger(logger)
+	return nil
+}
+
+// closeLogFile flushes and closes the file opened for --log-file, if any. It runs after each
+// command, and from exit, since os.Exit skips app.After.
+func closeLogFile(c *cli.Context) error {
+	if logFile == nil {
+		return nil
+	}
+	logFile.Sync()
+	err := logFile.Close()
+	logFile = nil
+	return err
+}
+
+// filterOptions gathers the global filtering flags.
+func filterOptions(c *cli.Context) lss.FilterOptions {
+	return lss.FilterOptions{
+		ShowHidden:    settingBool(c, "all"),
+		Include:       settingList(c, "include"),
+		Exclude:       settingList(c, "exclude"),
+		IncludeRegex:  settingList(c, "include-regex"),
+		ExcludeRegex:  settingList(c, "exclude-regex"),
+		Extensions:    settingList(c, "ext"),
+		OnlySequences: settingBool(c, "only-sequences"),
+		OnlySingles:   settingBool(c, "only-singles"),
+	}
+}
 
-	app.Run(os.Args)
+// descendFilter decides which subdirectories of dir --recursive walks: hidden directories are
+// skipped unless --all is given, as are those ignored by .lssignore rules.
+func descendFilter(c *cli.Context, dir string) (error, lss.Filter) {
+	filter := lss.HiddenFilter(settingBool(c, "all"))
+	if settingBool(c, "no-ignore") {
+		return nil, filter
+	}
+	err, ignore := lss.LoadIgnore(dir)
+	if err != nil {
+		return err, nil
+	}
+	return nil, filter.And(func(name string) bool {
+		return !ignore.Ignored(dir, name, func() bool { return true })
+	})
+}
+
+// listingFilter builds the filter chain described by the global filtering flags, along with
+// the .lssignore rules which apply to path.
+func listingFilter(c *cli.Context, path string) (error, lss.Filter) {
+	options := filterOptions(c)
+	err, filter := options.Filter()
+	if err != nil || settingBool(c, "no-ignore") {
+		return err, filter
+	}
+	err, ignore := lss.LoadIgnore(path)
+	if err != nil {
+		return err, nil
+	}
+	return nil, filter.And(ignore.Filter(path))
 }
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"github.com/codegangsta/cli"
+	"github.com/jlgerber/lss/pack"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+var manifestCommand = cli.Command{
+	Name:  "manifest",
+	Usage: "write a checksum manifest of a directory's sequences.",
+	Description: `Hash every member of each collapsed sequence in the directory (MD5 and SHA-256),
+	along with a combined per-sequence digest, and write the result as JSON or in a
+	sha256sum compatible text format.
+
+	lss manifest [--format json|sha256] [--output file] [Path]`,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "format, f",
+			Value: "json",
+			Usage: "manifest format: json or sha256.",
+		},
+		cli.StringFlag{
+			Name:  "output, o",
+			Usage: "write the manifest to a file rather than stdout.",
+		},
+		cli.IntFlag{
+			Name:  "jobs, j",
+			Value: runtime.NumCPU(),
+			Usage: "number of files to hash concurrently.",
+		},
+	},
+	Action: func(c *cli.Context) {
+		path := lss.GetCwdPath()
+		if len(c.Args()) > 0 {
+			path = c.Args()[0]
+		}
+		if err := loadSettings(path); err != nil {
+			fail(err)
+		}
+
+		format := c.String("format")
+		if format != "json" && format != "sha256" {
+			fail("unknown manifest format: " + format)
+		}
+
+		err, filter := listingFilter(c, path)
+		if err != nil {
+			fail(err)
+		}
+		err, contents := lss.FilteredListingFromPath(path, filter)
+		if err != nil {
+			fail(err)
+		}
+		err, manifest := lss.BuildManifest(path, contents, c.Int("jobs"))
+		if err != nil {
+			fail(err)
+		}
+
+		out := os.Stdout
+		if c.String("output") != "" {
+			out, err = os.Create(c.String("output"))
+			if err != nil {
+				fail(err)
+			}
+			defer out.Close()
+		}
+
+		if format == "json" {
+			err = manifest.WriteJSON(out)
+		} else {
+			err = manifest.WriteSums(out)
+		}
+		if err != nil {
+			fail(err)
+		}
+	},
+}
+
+var verifyCommand = cli.Command{
+	Name:  "verify",
+	Usage: "check a directory against a manifest.",
+	Description: `Re-hash the files recorded in a manifest written by 'lss manifest' (or by
+	sha256sum) and report any which are missing or changed, in range notation. The
+	directory defaults to the one containing the manifest. Exits with status 1 if any
+	problems are found, and 2 if the check could not be run.
+
+	lss verify <manifest> [Path]`,
+	Flags: []cli.Flag{
+		cli.IntFlag{
+			Name:  "jobs, j",
+			Value: runtime.NumCPU(),
+			Usage: "number of files to hash concurrently.",
+		},
+	},
+	Action: func(c *cli.Context) {
+		args := c.Args()
+		if len(args) < 1 {
+			fail("verify requires a manifest")
+		}
+		path := filepath.Dir(args[0])
+		if len(args) > 1 {
+			path = args[1]
+		}
+
+		f, err := os.Open(args[0])
+		if err != nil {
+			fail(err)
+		}
+		err, manifest := lss.ReadManifest(f)
+		f.Close()
+		if err != nil {
+			fail(err)
+		}
+
+		err, report := lss.VerifyManifest(path, manifest, c.Int("jobs"))
+		if err != nil {
+			fail(err)
+		}
+		for _, seq := range report.Mismatched {
+			fmt.Println("MISMATCH", seq.String())
+		}
+		for _, seq := range report.Missing {
+			fmt.Println("MISSING ", seq.String())
+		}
+		if !report.OK() {
+			exit(EXIT_PROBLEMS)
+		}
+		fmt.Println("OK", report.Checked, "files verified")
+	},
+}
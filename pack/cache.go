@@ -0,0 +1,357 @@
+package lss
+
+/*
+cache provides a persistent listing cache, for huge directories on slow filesystems which are
+listed again and again (eg by --recursive or --watch) but seldom change. Each entry holds the
+collapsed, unfiltered contents of one directory along with the directory's modification
+time, and is reused for as long as that time is unchanged, which costs a single stat rather
+than a full read of the directory. Filters are applied to the cached Sequences afterwards, so
+that one entry serves every combination of command line filters.
+
+Only directories holding at least CACHE_MIN_ENTRIES entries are stored, as smaller ones can
+be read in about the time it takes to stat them. Entries are not stored while the directory's
+modification time is within CACHE_RACY_WINDOW of the present, as further changes within the
+filesystem's timestamp granularity would go unnoticed.
+
+Note that NFS clients cache attributes (see actimeo), so a change made on another host may
+take that long to invalidate an entry, just as it takes that long to appear in a listing.
+*/
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	CACHE_VERSION     = 1               // format of the cache entries
+	CACHE_MIN_ENTRIES = 1000            // smallest directory worth caching
+	CACHE_RACY_WINDOW = 2 * time.Second // minimum age of a directory's mtime when cached
+)
+
+//-------------------------
+// Type CacheEntry
+//-------------------------
+
+// CacheEntry is the cached listing of a single directory.
+type CacheEntry struct {
+	Path      string     // absolute path of the directory
+	ModTime   time.Time  // modification time of the directory when it was read
+	Sequences []Sequence // the collapsed, unfiltered contents of the directory
+	Subdirs   []string   // names of the directory's subdirectories
+}
+
+//-------------------------
+// Type Cache
+//-------------------------
+
+// Cache is a directory of CacheEntries, one file per cached directory.
+type Cache struct {
+	Dir string
+}
+
+// NewCache returns a Cache storing its entries within dir.
+func NewCache(dir string) *Cache {
+	return &Cache{Dir: dir}
+}
+
+// DefaultCacheDir returns the directory holding the user's listing cache
+// (eg ~/.cache/lss/listings), or "" if the user's cache directory cannot be determined.
+func DefaultCacheDir() string {
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(cache, "lss", "listings")
+}
+
+// Collapse returns the listing of the directory path, from the cache if the directory is
+// unchanged since it was cached, and otherwise by reading the directory, in which case the
+// cache is updated. Failing to update the cache is not an error.
+func (c *Cache) Collapse(path string) (error, *CacheEntry) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err, nil
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return err, nil
+	}
+	if !info.IsDir() {
+		return errors.New("Supplied path:'" + path + "' is not a directory"), nil
+	}
+	if ok, entry := c.Lookup(abs, info.ModTime()); ok {
+		logger.Info("cache hit", "path", abs)
+		return nil, entry
+	}
+	logger.Info("cache miss", "path", abs)
+
+	err, entry := readCacheEntry(abs, info.ModTime())
+	if err != nil {
+		return err, nil
+	}
+	switch {
+	case entry.entries < CACHE_MIN_ENTRIES:
+		logger.Info("not cached", "path", abs, "reason", "too few entries")
+	case time.Since(info.ModTime()) < CACHE_RACY_WINDOW:
+		logger.Info("not cached", "path", abs, "reason", "modified too recently")
+	default:
+		if err := c.Store(entry.CacheEntry); err != nil {
+			logger.Warn("cache store failed", "path", abs, "error", err)
+		}
+	}
+	return nil, entry.CacheEntry
+}
+
+// Lookup returns the entry for the directory at the absolute path abs, provided it was
+// cached when the directory's modification time was modTime.
+func (c *Cache) Lookup(abs string, modTime time.Time) (bool, *CacheEntry) {
+	err, entry := readCacheFile(c.entryPath(abs))
+	if err != nil || entry.Path != abs || !entry.ModTime.Equal(modTime) {
+		return false, nil
+	}
+	return true, entry
+}
+
+// Store writes entry to the cache, replacing any previous entry for the same directory.
+func (c *Cache) Store(entry *CacheEntry) error {
+	path := c.entryPath(entry.Path)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	// write alongside, then rename, so that readers never see a partial entry
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".entry-*")
+	if err != nil {
+		return err
+	}
+	err = writeCacheFile(tmp, entry)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// Prune removes the entries which can no longer be used - those whose directories have
+// changed or vanished, and those which are unreadable or of another version - along with any
+// entry not written within maxAge, if maxAge is positive. It returns the number of entries
+// removed.
+func (c *Cache) Prune(maxAge time.Duration) (error, int) {
+	removed := 0
+	err := filepath.Walk(c.Dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".json") {
+			return nil
+		}
+		if cacheEntryStale(path, info, maxAge) {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+			removed++
+		}
+		return nil
+	})
+	return err, removed
+}
+
+// Clear removes every entry from the cache.
+func (c *Cache) Clear() error {
+	return os.RemoveAll(c.Dir)
+}
+
+// entryPath returns the path of the file holding the entry for the directory abs.
+func (c *Cache) entryPath(abs string) string {
+	sum := sha256.Sum256([]byte(abs))
+	key := hex.EncodeToString(sum[:])
+	return filepath.Join(c.Dir, key[:2], key+".json")
+}
+
+//-------------------------
+// Cached Listing Functions
+//-------------------------
+
+// CachedCollapsePath behaves as CollapsePath, but consults cache, if it is not nil, before
+// reading the directory.
+func CachedCollapsePath(cache *Cache, path string, filter func(string) bool) (error, []Sequence) {
+	if cache == nil {
+		return CollapsePath(path, filter)
+	}
+	err, entry := cache.Collapse(path)
+	if err != nil {
+		return err, []Sequence{}
+	}
+	return nil, FilterSequences(entry.Sequences, filter)
+}
+
+// FilterSequences returns the Sequences formed by the members of seqs which the filter keeps.
+// If the filter is nil, or keeps every member, seqs is returned unchanged; the members are
+// only regrouped once the filter drops one of them.
+func FilterSequences(seqs []Sequence, filter func(string) bool) []Sequence {
+	if filter == nil || keepsAll(seqs, filter) {
+		return seqs
+	}
+	regrouped, _ := RegroupSequences(seqs, filter, POLICY_AUTO)
+	return regrouped
+}
+
+//-----------------------------------------
+// Private Utility Types & Functions
+//-----------------------------------------
+
+// keepsAll returns true if filter keeps every member of seqs.
+func keepsAll(seqs []Sequence, filter func(string) bool) bool {
+	for i := range seqs {
+		seq := &seqs[i]
+		if seq.Single() {
+			if !filter(seq.Prefix) {
+				return false
+			}
+			continue
+		}
+		for _, frame := range seq.Frames {
+			if !filter(seq.Name(frame)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// cacheFile is the on disk form of a CacheEntry. Frames are stored as runs, which keeps the
+// entries of long sequences small.
+type cacheFile struct {
+	Version   int             `json:"version"`
+	Path      string          `json:"path"`
+	ModTime   time.Time       `json:"mtime"`
+	Sequences []cacheSequence `json:"sequences"`
+	Subdirs   []string        `json:"subdirs"`
+}
+
+type cacheSequence struct {
+	Prefix    string   `json:"prefix"`
+	Padding   int      `json:"padding"`
+	Extension string   `json:"extension,omitempty"`
+	Runs      [][2]int `json:"runs,omitempty"`
+}
+
+// countedEntry is a freshly read CacheEntry, along with the number of entries read.
+type countedEntry struct {
+	*CacheEntry
+	entries int
+}
+
+// readCacheEntry reads and collapses the directory abs, without filtering.
+func readCacheEntry(abs string, modTime time.Time) (error, countedEntry) {
+	entry := countedEntry{CacheEntry: &CacheEntry{Path: abs, ModTime: modTime, Subdirs: []string{}}}
+	f, err := os.Open(abs)
+	if err != nil {
+		return err, entry
+	}
+	defer f.Close()
+
+	builder := NewSequenceBuilder()
+	for {
+		dirents, err := f.ReadDir(LISTING_BATCH)
+		for _, dirent := range dirents {
+			builder.Add(dirent.Name())
+			if dirent.IsDir() {
+				entry.Subdirs = append(entry.Subdirs, dirent.Name())
+			}
+		}
+		entry.entries += len(dirents)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err, entry
+		}
+	}
+	entry.Sequences = builder.Sequences()
+	return nil, entry
+}
+
+// writeCacheFile encodes entry to w.
+func writeCacheFile(w io.Writer, entry *CacheEntry) error {
+	file := cacheFile{
+		Version:   CACHE_VERSION,
+		Path:      entry.Path,
+		ModTime:   entry.ModTime,
+		Sequences: make([]cacheSequence, len(entry.Sequences)),
+		Subdirs:   entry.Subdirs,
+	}
+	for i, seq := range entry.Sequences {
+		cs := cacheSequence{Prefix: seq.Prefix, Padding: seq.Padding, Extension: seq.Extension}
+		for j := 0; j < len(seq.Frames); {
+			k := j
+			for k+1 < len(seq.Frames) && seq.Frames[k+1] == seq.Frames[k]+1 {
+				k++
+			}
+			cs.Runs = append(cs.Runs, [2]int{seq.Frames[j], seq.Frames[k]})
+			j = k + 1
+		}
+		file.Sequences[i] = cs
+	}
+	return json.NewEncoder(w).Encode(&file)
+}
+
+// readCacheFile decodes the cache entry stored at path.
+func readCacheFile(path string) (error, *CacheEntry) {
+	f, err := os.Open(path)
+	if err != nil {
+		return err, nil
+	}
+	defer f.Close()
+
+	var file cacheFile
+	if err := json.NewDecoder(f).Decode(&file); err != nil {
+		return err, nil
+	}
+	if file.Version != CACHE_VERSION {
+		return errors.New(path + ": unsupported cache version"), nil
+	}
+	entry := &CacheEntry{
+		Path:      file.Path,
+		ModTime:   file.ModTime,
+		Sequences: make([]Sequence, len(file.Sequences)),
+		Subdirs:   file.Subdirs,
+	}
+	for i, cs := range file.Sequences {
+		seq := Sequence{Prefix: cs.Prefix, Padding: cs.Padding, Extension: cs.Extension}
+		for _, run := range cs.Runs {
+			for frame := run[0]; frame <= run[1]; frame++ {
+				seq.Frames = append(seq.Frames, frame)
+			}
+		}
+		entry.Sequences[i] = seq
+	}
+	return nil, entry
+}
+
+// cacheEntryStale returns true if the entry stored at path should be pruned.
+func cacheEntryStale(path string, info os.FileInfo, maxAge time.Duration) bool {
+	if maxAge > 0 && time.Since(info.ModTime()) > maxAge {
+		return true
+	}
+	err, entry := readCacheFile(path)
+	if err != nil {
+		return true
+	}
+	dir, err := os.Stat(entry.Path)
+	return err != nil || !dir.IsDir() || !dir.ModTime().Equal(entry.ModTime)
+}
@@ -0,0 +1,186 @@
+package lss
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// makeCachedDir creates a directory big enough to be cached, whose modification time is
+// outside the racy window.
+func makeCachedDir(t *testing.T) (string, []string) {
+	names := benchNames(CACHE_MIN_ENTRIES)
+	dir := makeListing(t, names)
+	os.Mkdir(filepath.Join(dir, "sub"), 0755)
+	ageDir(t, dir)
+	return dir, append(names, "sub")
+}
+
+// ageDir backdates the modification time of dir by an hour.
+func ageDir(t *testing.T, dir string) {
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(dir, old, old); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCache_RoundTrip(t *testing.T) {
+	cache := NewCache(t.TempDir())
+	entry := &CacheEntry{
+		Path:    "/shows/abc/shot1",
+		ModTime: time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC),
+		Sequences: SequencesFromStringSlice([]string{
+			"foo.0001.exr", "foo.0002.exr", "foo.0004.exr", "bar.1.dpx", "notes.txt",
+		}),
+		Subdirs: []string{"render"},
+	}
+	if err := cache.Store(entry); err != nil {
+		t.Fatal(err)
+	}
+	ok, got := cache.Lookup(entry.Path, entry.ModTime)
+	if !ok || !reflect.DeepEqual(got, entry) {
+		t.Error("Lookup returned", ok, got, "Should Be:", entry)
+	}
+	if ok, _ := cache.Lookup(entry.Path, entry.ModTime.Add(time.Second)); ok {
+		t.Error("Lookup should miss once the modification time changes")
+	}
+	if ok, _ := cache.Lookup("/shows/abc/shot2", entry.ModTime); ok {
+		t.Error("Lookup should miss for another directory")
+	}
+}
+
+func TestCache_Collapse(t *testing.T) {
+	cache := NewCache(t.TempDir())
+	dir, names := makeCachedDir(t)
+
+	err, entry := cache.Collapse(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := SequencesFromStringSlice(names); !reflect.DeepEqual(entry.Sequences, want) {
+		t.Error("Collapse was", entry.Sequences, "Should Be:", want)
+	}
+	if !testEq(entry.Subdirs, []string{"sub"}) {
+		t.Error("Collapse found subdirectories", entry.Subdirs)
+	}
+
+	// the cached entry is used for as long as the modification time is unchanged
+	cached, _ := os.Stat(dir)
+	os.WriteFile(filepath.Join(dir, "new.txt"), []byte{}, 0644)
+	info, _ := os.Stat(dir)
+	os.Chtimes(dir, cached.ModTime(), cached.ModTime())
+	if _, entry = cache.Collapse(dir); len(entry.Sequences) != len(SequencesFromStringSlice(names)) {
+		t.Error("Collapse did not use the cached entry")
+	}
+	os.Chtimes(dir, info.ModTime(), info.ModTime())
+	if _, entry = cache.Collapse(dir); len(entry.Sequences) != len(SequencesFromStringSlice(names))+1 {
+		t.Error("Collapse used a stale entry")
+	}
+}
+
+func TestCache_SkipsSmallAndRacyDirectories(t *testing.T) {
+	cache := NewCache(t.TempDir())
+	small := makeListing(t, []string{"foo.0001.exr", "foo.0002.exr"})
+	ageDir(t, small)
+	racy := makeListing(t, benchNames(CACHE_MIN_ENTRIES))
+
+	for _, dir := range []string{small, racy} {
+		if err, _ := cache.Collapse(dir); err != nil {
+			t.Fatal(err)
+		}
+		abs, _ := filepath.Abs(dir)
+		info, _ := os.Stat(dir)
+		if ok, _ := cache.Lookup(abs, info.ModTime()); ok {
+			t.Error(dir, "should not have been cached")
+		}
+	}
+}
+
+func TestCache_FilterSequences(t *testing.T) {
+	names := []string{"foo.0001.exr", "foo.0002.exr", ".foo.0003.exr", "bar.10.exr", "bar.11.dpx", ".hidden"}
+	seqs := SequencesFromStringSlice(names)
+	filter := HiddenFilter(false).And(ExtFilter("exr"))
+
+	kept := []string{}
+	for _, name := range names {
+		if filter(name) {
+			kept = append(kept, name)
+		}
+	}
+	if got, want := FilterSequences(seqs, filter), SequencesFromStringSlice(kept); !reflect.DeepEqual(got, want) {
+		t.Error("FilterSequences was", got, "Should Be:", want)
+	}
+	if got := FilterSequences(seqs, nil); !reflect.DeepEqual(got, seqs) {
+		t.Error("FilterSequences with a nil filter was", got)
+	}
+	// a filter which drops nothing leaves the cached sequences as they are
+	if got := FilterSequences(seqs, HiddenFilter(true)); &got[0] != &seqs[0] {
+		t.Error("FilterSequences regrouped although nothing was filtered out")
+	}
+}
+
+func TestCache_CachedCollapsePath(t *testing.T) {
+	cache := NewCache(t.TempDir())
+	dir, _ := makeCachedDir(t)
+	filter := HiddenFilter(false).And(ExtFilter("exr"))
+
+	err, want := CollapsePath(dir, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		err, got := CachedCollapsePath(cache, dir, filter)
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Error("CachedCollapsePath was", got, err, "Should Be:", want)
+		}
+	}
+	if err, _ := CachedCollapsePath(cache, filepath.Join(dir, "missing"), nil); err == nil {
+		t.Error("CachedCollapsePath of a missing directory should fail")
+	}
+}
+
+func TestCache_Walk(t *testing.T) {
+	root := makeTree(t)
+	cache := NewCache(t.TempDir())
+	walk := func(cache *Cache) []WalkResult {
+		results := []WalkResult{}
+		for result := range Walk(context.Background(), root, WalkOptions{Jobs: 4, Cache: cache}) {
+			results = append(results, result)
+		}
+		return results
+	}
+	if got, want := walk(cache), walk(nil); !reflect.DeepEqual(got, want) {
+		t.Error("cached walk was", got, "Should Be:", want)
+	}
+}
+
+func TestCache_Prune(t *testing.T) {
+	cache := NewCache(t.TempDir())
+	dir, _ := makeCachedDir(t)
+	gone, _ := makeCachedDir(t)
+	for _, d := range []string{dir, gone} {
+		if err, _ := cache.Collapse(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.RemoveAll(gone)
+
+	err, removed := cache.Prune(0)
+	if err != nil || removed != 1 {
+		t.Error("Prune removed", removed, err, "Should Be: 1")
+	}
+	err, removed = cache.Prune(time.Nanosecond)
+	if err != nil || removed != 1 {
+		t.Error("Prune by age removed", removed, err, "Should Be: 1")
+	}
+
+	if err := cache.Clear(); err != nil {
+		t.Error(err)
+	}
+	if err, removed = cache.Prune(0); err != nil || removed != 0 {
+		t.Error("Prune of a cleared cache returned", removed, err)
+	}
+}
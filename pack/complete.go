@@ -0,0 +1,70 @@
+package lss
+
+/*
+complete provides the completion of partial paths for the shell completion scripts. Rather
+than offering every member of a sequence, as the shells' own file completion would, the
+members are collapsed into their pattern, so that completing
+
+    renders/beau
+
+offers renders/beauty_v012.%04d.exr, rather than thousands of frame names. Should the word
+run on past a sequence's prefix (eg renders/beauty_v012.00), the user is picking out frames,
+and the members which match are offered instead.
+*/
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// CompletePath returns the completions of word, a partial path: the directories, sequence
+// patterns and single files, within the directory word names, which begin with the rest of
+// word. Patterns are written in style, and directories end with a separator. Hidden entries
+// are only offered if the rest of word begins with a period.
+func CompletePath(word string, style Style) (error, []string) {
+	dir, base := "", word
+	if i := strings.LastIndexByte(word, filepath.Separator); i >= 0 {
+		dir, base = word[:i+1], word[i+1:]
+	}
+	path := dir
+	switch {
+	case path == "":
+		path = "."
+	case strings.HasPrefix(path, "~"+string(filepath.Separator)):
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+
+	err, seqs := CollapsePath(path, func(name string) bool {
+		return strings.HasPrefix(name, base) && (name[0] != '.' || strings.HasPrefix(base, "."))
+	})
+	if err != nil {
+		return err, nil
+	}
+
+	isDir := func(name string) bool {
+		info, err := os.Stat(filepath.Join(path, name))
+		return err == nil && info.IsDir()
+	}
+	completions := []string{}
+	for i := range seqs {
+		seq := &seqs[i]
+		names := seq.Names()
+		// numbered directories (eg shot010) are destinations in their own right
+		if pattern := style.Pattern(seq); seq.Len() > 1 && strings.HasPrefix(pattern, base) && !isDir(names[0]) {
+			completions = append(completions, dir+pattern)
+			continue
+		}
+		for _, name := range names {
+			if isDir(name) {
+				name += string(filepath.Separator)
+			}
+			completions = append(completions, dir+name)
+		}
+	}
+	sort.Strings(completions)
+	return nil, completions
+}
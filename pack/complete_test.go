@@ -0,0 +1,57 @@
+package lss
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestComplete_CompletePath(t *testing.T) {
+	names := []string{".hidden.0001.exr", "beauty_v012.0001.exr", "beauty_v012.0002.exr", "beauty_v012.0003.exr",
+		"beauty_v013.0001.exr", "bg.1001.dpx", "notes.txt"}
+	dir := makeListing(t, names)
+	for _, shot := range []string{"shot010", "shot020", "plates"} {
+		os.Mkdir(filepath.Join(dir, shot), 0755)
+	}
+	prefix := dir + string(filepath.Separator)
+
+	tests := map[string][]string{
+		"beau":          {"beauty_v012.%04d.exr", "beauty_v013.0001.exr"},
+		"beauty_v012.0": {"beauty_v012.0001.exr", "beauty_v012.0002.exr", "beauty_v012.0003.exr"},
+		"b":             {"beauty_v012.%04d.exr", "beauty_v013.0001.exr", "bg.1001.dpx"},
+		"shot":          {"shot010/", "shot020/"},
+		"pl":            {"plates/"},
+		".":             {".hidden.0001.exr"},
+		"x":             {},
+	}
+	for word, expected := range tests {
+		err, got := CompletePath(prefix+word, Style{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range expected {
+			expected[i] = prefix + expected[i]
+		}
+		if !testEq(got, expected) {
+			t.Errorf("CompletePath(%q) returned %v Should Be: %v", word, got, expected)
+		}
+	}
+
+	if err, got := CompletePath(prefix+"beauty_v012", Style{Notation: NOTATION_HASH}); err != nil || !testEq(got, []string{prefix + "beauty_v012.####.exr"}) {
+		t.Error("CompletePath in hash notation returned", got, err)
+	}
+	if err, _ := CompletePath(prefix+"missing/be", Style{}); err == nil {
+		t.Error("CompletePath of a missing directory did not fail")
+	}
+}
+
+func TestComplete_CompletePathRelative(t *testing.T) {
+	dir := makeListing(t, []string{"foo.0001.exr", "foo.0002.exr"})
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(dir)
+
+	if err, got := CompletePath("f", Style{}); err != nil || !testEq(got, []string{"foo.%04d.exr"}) {
+		t.Error("CompletePath in the working directory returned", got, err)
+	}
+}
@@ -0,0 +1,310 @@
+package lss
+
+/*
+config provides the settings files which supply defaults for lss's flags, so that studio and
+show conventions need no wrapper scripts or aliases. Defaults are resolved from, in increasing
+order of precedence:
+
+- the user's config file (eg ~/.config/lss/config)
+- the .lssrc files of the listed directory's ancestors, from the root down
+- the .lssrc file of the listed directory itself
+- LSS_* environment variables, named after the setting (eg LSS_SORT, LSS_PADDING_POLICY)
+
+Flags given on the command line override them all.
+
+Settings files are ini like: blank lines and lines starting with # are skipped, [name] starts
+a section, and every other line is a key = value pair. The value runs to the end of the line,
+and is used as written, so that templates need no quoting. Values may be double quoted (eg
+list-sep = " ") to keep leading or trailing spaces.
+
+[defaults]  - default values of settings, named as their flags (see SETTINGS). Lists (eg
+              exclude) are comma separated, and switches (eg all) are true or false.
+[templates] - named output templates, for use with --template (see template.go)
+
+    [defaults]
+    notation = hash
+    exclude  = *.tmp, .DS_Store
+    sort     = time
+
+    [templates]
+    nuke  = {{.Path}} {{.First}}-{{.Last}}
+    sizes = {{pad 30 .Pattern}} {{human .Size}}
+*/
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ConfigFileName is the name of the per-directory settings file.
+const ConfigFileName = ".lssrc"
+
+// Kinds of setting value.
+const (
+	SETTING_STRING = iota // a single value
+	SETTING_BOOL          // true or false
+	SETTING_LIST          // a comma separated list
+)
+
+// SETTINGS lists the settings which may be given defaults, by the name of their flag, along
+// with the kind of value each takes. colors has no flag; it holds the LSS_COLORS scheme.
+var SETTINGS = []struct {
+	Name string
+	Kind int
+}{
+	{"all", SETTING_BOOL},
+	{"include", SETTING_LIST},
+	{"exclude", SETTING_LIST},
+	{"include-regex", SETTING_LIST},
+	{"exclude-regex", SETTING_LIST},
+	{"ext", SETTING_LIST},
+	{"only-sequences", SETTING_BOOL},
+	{"only-singles", SETTING_BOOL},
+	{"no-ignore", SETTING_BOOL},
+	{"sort", SETTING_STRING},
+	{"reverse", SETTING_BOOL},
+	{"notation", SETTING_STRING},
+	{"range-sep", SETTING_STRING},
+	{"list-sep", SETTING_STRING},
+	{"color", SETTING_STRING},
+	{"colors", SETTING_STRING},
+	{"columns", SETTING_BOOL},
+	{"fps", SETTING_STRING},
+	{"padding-policy", SETTING_STRING},
+	{"template", SETTING_STRING},
+}
+
+//-------------------------
+// Type Config
+//-------------------------
+
+// Config holds the settings read from settings files and the environment.
+type Config struct {
+	Defaults  map[string]string // default values of settings, by name
+	Sources   map[string]string // where each default was set (a file, or a variable)
+	Templates map[string]string // named output templates
+	Files     []string          // the settings files read, in order
+}
+
+// NewConfig returns an empty Config.
+func NewConfig() *Config {
+	return &Config{Defaults: map[string]string{}, Sources: map[string]string{}, Templates: map[string]string{}}
+}
+
+// ParseConfig reads a Config from r. name is used to identify r in errors.
+func ParseConfig(name string, r io.Reader) (error, *Config) {
+	config := NewConfig()
+	section := ""
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		where := name + ":" + strconv.Itoa(lineNo) + ": "
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || line[0] == '#':
+			continue
+		case line[0] == '[':
+			if !strings.HasSuffix(line, "]") {
+				return errors.New(where + "unterminated section '" + line + "'"), nil
+			}
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			if section != "defaults" && section != "templates" {
+				return errors.New(where + "unknown section [" + section + "]"), nil
+			}
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || key == "" {
+			return errors.New(where + "expected key = value"), nil
+		}
+		if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return errors.New(where + "bad quoted value " + value), nil
+			}
+			value = unquoted
+		}
+		switch section {
+		case "defaults":
+			if err := checkSetting(key, value); err != nil {
+				return errors.New(where + err.Error()), nil
+			}
+			config.Defaults[key] = value
+			config.Sources[key] = name
+		case "templates":
+			if err, _ := ParseTemplate(value); err != nil {
+				return errors.New(where + "template " + key + ": " + err.Error()), nil
+			}
+			config.Templates[key] = value
+		default:
+			return errors.New(where + "'" + key + "' is outside of any section"), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err, nil
+	}
+	config.Files = []string{name}
+	return nil, config
+}
+
+// LoadConfig reads the settings file at path. A missing file yields an empty Config.
+func LoadConfig(path string) (error, *Config) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, NewConfig()
+		}
+		return err, nil
+	}
+	defer f.Close()
+	return ParseConfig(path, f)
+}
+
+// LoadConfigs resolves the settings which apply to the directory dir, from the user's config
+// file, the .lssrc files of dir and its ancestors, and the environment, as described above.
+// getenv is consulted for the environment; it is usually os.LookupEnv.
+func LoadConfigs(dir string, getenv func(string) (string, bool)) (error, *Config) {
+	config := NewConfig()
+	paths := []string{}
+	if user := UserConfigPath(); user != "" {
+		paths = append(paths, user)
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err, nil
+	}
+	// collect the directory and its ancestors, then read them from the root down
+	rcs := []string{}
+	for d := abs; ; d = filepath.Dir(d) {
+		rcs = append(rcs, filepath.Join(d, ConfigFileName))
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	for i := len(rcs) - 1; i >= 0; i-- {
+		paths = append(paths, rcs[i])
+	}
+
+	for _, path := range paths {
+		err, file := LoadConfig(path)
+		if err != nil {
+			return err, nil
+		}
+		config.Merge(file)
+	}
+	if err := config.MergeEnv(getenv); err != nil {
+		return err, nil
+	}
+	return nil, config
+}
+
+// Merge overrides the settings and templates of c with those of other.
+func (c *Config) Merge(other *Config) {
+	for key, value := range other.Defaults {
+		c.Defaults[key] = value
+		c.Sources[key] = other.Sources[key]
+	}
+	for name, text := range other.Templates {
+		c.Templates[name] = text
+	}
+	c.Files = append(c.Files, other.Files...)
+}
+
+// MergeEnv overrides the settings of c with those given by LSS_* environment variables, as
+// looked up by getenv.
+func (c *Config) MergeEnv(getenv func(string) (string, bool)) error {
+	for _, setting := range SETTINGS {
+		name := EnvName(setting.Name)
+		value, ok := getenv(name)
+		if !ok {
+			continue
+		}
+		if err := checkSetting(setting.Name, value); err != nil {
+			return errors.New(name + ": " + err.Error())
+		}
+		c.Defaults[setting.Name] = value
+		c.Sources[setting.Name] = "$" + name
+	}
+	return nil
+}
+
+// EnvName returns the name of the environment variable holding the default of the setting
+// (eg LSS_PADDING_POLICY for padding-policy).
+func EnvName(setting string) string {
+	return "LSS_" + strings.ToUpper(strings.ReplaceAll(setting, "-", "_"))
+}
+
+// SettingKind returns the kind of value the setting takes, or -1 if there is no such setting.
+func SettingKind(name string) int {
+	for _, setting := range SETTINGS {
+		if setting.Name == name {
+			return setting.Kind
+		}
+	}
+	return -1
+}
+
+// SplitList splits the value of a list setting into its trimmed, non empty members.
+func SplitList(value string) []string {
+	list := []string{}
+	for _, member := range strings.Split(value, ",") {
+		if member = strings.TrimSpace(member); member != "" {
+			list = append(list, member)
+		}
+	}
+	return list
+}
+
+// UserConfigPath returns the path of the user's config file, or "" if the user's config
+// directory cannot be determined.
+func UserConfigPath() string {
+	config, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(config, "lss", "config")
+}
+
+// Bool returns the value of a switch setting, and whether it has a default.
+func (c *Config) Bool(name string) (bool, bool) {
+	value, ok := c.Defaults[name]
+	if !ok {
+		return false, false
+	}
+	b, _ := strconv.ParseBool(value)
+	return b, true
+}
+
+// Template returns the template named by name, or if there is no template of that name and
+// name looks like a template (ie it contains "{{"), name parsed as a template itself.
+func (c *Config) Template(name string) (error, *Template) {
+	if text, ok := c.Templates[name]; ok {
+		return ParseTemplate(text)
+	}
+	if !strings.Contains(name, "{{") {
+		return errors.New("unknown template:'" + name + "'"), nil
+	}
+	return ParseTemplate(name)
+}
+
+//-----------------------------------------
+// Private Utility Types & Functions
+//-----------------------------------------
+
+// checkSetting returns an error if value is not a valid value for the setting name.
+func checkSetting(name string, value string) error {
+	switch SettingKind(name) {
+	case -1:
+		return errors.New("unknown setting '" + name + "'")
+	case SETTING_BOOL:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return errors.New(name + " must be true or false, not '" + value + "'")
+		}
+	}
+	return nil
+}
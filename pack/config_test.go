@@ -0,0 +1,147 @@
+package lss
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfig_Parse(t *testing.T) {
+	text := `
+# studio templates
+[templates]
+nuke  = {{.Path}} {{.First}}-{{.Last}}
+equal = {{if eq .Count 1}}one{{end}}
+`
+	err, config := ParseConfig("test", strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Templates["nuke"] != "{{.Path}} {{.First}}-{{.Last}}" || config.Templates["equal"] != "{{if eq .Count 1}}one{{end}}" {
+		t.Error("templates were", config.Templates)
+	}
+
+	if err, _ := config.Template("nuke"); err != nil {
+		t.Error("named template:", err)
+	}
+	if err, _ := config.Template("{{.Pattern}}"); err != nil {
+		t.Error("literal template:", err)
+	}
+	if err, _ := config.Template("missing"); err == nil {
+		t.Error("an unknown template name should fail")
+	}
+}
+
+func TestConfig_Errors(t *testing.T) {
+	tests := map[string]string{
+		"[templates\n":              "test:1: unterminated section",
+		"[colours]\n":               "test:1: unknown section [colours]",
+		"nuke = {{.Path}}\n":        "test:1: 'nuke' is outside of any section",
+		"[templates]\n\nnuke\n":     "test:3: expected key = value",
+		"[templates]\nbad = {{.X\n": "test:2: template bad:",
+	}
+	for text, expected := range tests {
+		err, _ := ParseConfig("test", strings.NewReader(text))
+		if err == nil || !strings.HasPrefix(err.Error(), expected) {
+			t.Errorf("%q failed with %v Should Be: %s", text, err, expected)
+		}
+	}
+}
+
+func TestConfig_Load(t *testing.T) {
+	dir := t.TempDir()
+	err, config := LoadConfig(filepath.Join(dir, "missing"))
+	if err != nil || len(config.Templates) != 0 {
+		t.Error("a missing config file loaded as", config, err)
+	}
+	path := filepath.Join(dir, "config")
+	os.WriteFile(path, []byte("[templates]\nshort = {{.Pattern}}\n"), 0644)
+	if err, config = LoadConfig(path); err != nil || config.Templates["short"] != "{{.Pattern}}" {
+		t.Error("config loaded as", config, err)
+	}
+}
+
+func TestConfig_Defaults(t *testing.T) {
+	text := `
+[defaults]
+notation = hash
+exclude  = *.tmp, .DS_Store ,
+all      = true
+list-sep = " "
+`
+	err, config := ParseConfig("rc", strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Defaults["notation"] != "hash" || config.Sources["notation"] != "rc" || config.Defaults["list-sep"] != " " {
+		t.Error("defaults were", config.Defaults, config.Sources)
+	}
+	if list := SplitList(config.Defaults["exclude"]); !testEq(list, []string{"*.tmp", ".DS_Store"}) {
+		t.Error("exclude split as", list)
+	}
+	if all, ok := config.Bool("all"); !all || !ok {
+		t.Error("all was", all, ok)
+	}
+	if _, ok := config.Bool("reverse"); ok {
+		t.Error("reverse should have no default")
+	}
+
+	for text, expected := range map[string]string{
+		"[defaults]\nall = yes\n":      "rc:2: all must be true or false",
+		"[defaults]\ncolour = 1\n":     "rc:2: unknown setting 'colour'",
+		"[defaults]\nsort = \"\\q\"\n": "rc:2: bad quoted value",
+	} {
+		err, _ := ParseConfig("rc", strings.NewReader(text))
+		if err == nil || !strings.HasPrefix(err.Error(), expected) {
+			t.Errorf("%q failed with %v Should Be: %s", text, err, expected)
+		}
+	}
+}
+
+func TestConfig_LoadConfigs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("HOME", home)
+	user := UserConfigPath()
+	os.MkdirAll(filepath.Dir(user), 0755)
+	os.WriteFile(user, []byte("[defaults]\nsort = time\nnotation = hash\nreverse = true\n[templates]\nshort = {{.Pattern}}\n"), 0644)
+
+	show := t.TempDir()
+	shot := filepath.Join(show, "seq", "shot")
+	os.MkdirAll(shot, 0755)
+	os.WriteFile(filepath.Join(show, ConfigFileName), []byte("[defaults]\nnotation = houdini\nsort = count\n"), 0644)
+	os.WriteFile(filepath.Join(shot, ConfigFileName), []byte("[defaults]\nsort = size\n"), 0644)
+
+	env := map[string]string{"LSS_PADDING_POLICY": "split", "LSS_SORT_ORDER": "ignored"}
+	getenv := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+	err, config := LoadConfigs(shot, getenv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"sort":           filepath.Join(shot, ConfigFileName) + ":size",
+		"notation":       filepath.Join(show, ConfigFileName) + ":houdini",
+		"reverse":        user + ":true",
+		"padding-policy": "$LSS_PADDING_POLICY:split",
+	}
+	for name, want := range expected {
+		if got := config.Sources[name] + ":" + config.Defaults[name]; got != want {
+			t.Error(name, "was", got, "Should Be:", want)
+		}
+	}
+	if len(config.Defaults) != len(expected) || config.Templates["short"] != "{{.Pattern}}" {
+		t.Error("config was", config.Defaults, config.Templates)
+	}
+	if want := []string{user, filepath.Join(show, ConfigFileName), filepath.Join(shot, ConfigFileName)}; !testEq(config.Files, want) {
+		t.Error("read", config.Files, "Should Be:", want)
+	}
+
+	env["LSS_ONLY_SINGLES"] = "perhaps"
+	if err, _ := LoadConfigs(shot, getenv); err == nil || !strings.HasPrefix(err.Error(), "LSS_ONLY_SINGLES:") {
+		t.Error("a bad environment variable failed with", err)
+	}
+}
@@ -4,7 +4,8 @@ var AppHelpTemplate string = `NAME:
     {{.Name}} - {{.Usage}}
 
 	USAGE:
-	   {{.Name}} {{if .Flags}}[global options]{{end}} [Path]
+	   {{.Name}} {{if .Flags}}[global options]{{end}} [Path...]
+	   {{.Name}} {{if .Flags}}[global options]{{end}} command [command options] [arguments...]
 
 	VERSION:
 	   {{.Version}}{{if len .Authors}}
@@ -14,10 +15,16 @@ var AppHelpTemplate string = `NAME:
 
 	COMMANDS:
 	   {{range .Commands}}{{join .Names ", "}}{{ "\t" }}{{.Usage}}
-	   {{end}}{{if .Flags}}
+	   {{end}}
+	   Use "{{.Name}} help command" for the options and arguments of each command.{{if .Flags}}
+
 	GLOBAL OPTIONS:
 	   {{range .Flags}}{{.}}
 	   {{end}}{{end}}
+	EXIT STATUS:
+	   0  success
+	   1  ran, and found problems (eg verify found changed or missing files)
+	   2  could not run (eg a bad flag or setting, or an unreadable directory)
 `
 
 var Usage = `Print a directory listing, with file ranges presented in terse, 
@@ -34,5 +41,31 @@ var Usage = `Print a directory listing, with file ranges presented in terse,
 	foo.%02d.exr 1-3
 
 	The user may pass an explicit directory to the command. If no directory is provided, lss uses
-	the current working directory.
+	the current working directory. Several directories may be passed, in which case each is listed
+	in turn under a header. Files may be passed as well (eg a shell expanded foo.*.exr), in which
+	case they are collapsed together as a set.
+
+	With --recursive (-R), each directory's subdirectories are listed as well. Directories are
+	read --jobs at a time, which helps a great deal on high latency network storage, but are
+	always printed in the same order.
+
+	The collapsed listings of large directories are cached (see lss cache), and reused for as
+	long as the directory is unmodified. Use --no-cache to read every directory afresh.
+
+	--watch re-lists the directory every --interval (2s by default), showing what changed, and
+	with --expect, when each sequence should be complete.
+
+	Frames such as foo.100.exr, which may be either padded or unpadded, are placed by the rest of
+	their sequence. Use --explain to see how, and --padding-policy to override it.
+
+	Output to a terminal is colored (see --color and LSS_COLORS), and -C lists entries in columns
+	across the terminal.
+
+	--template prints each sequence through a Go template instead (eg '{{.Pattern}} {{.Count}}'),
+	or through one named in the [templates] section of a settings file.
+
+	Defaults for the flags are read from ~/.config/lss/config, from .lssrc files in each listed
+	directory and its ancestors, and from LSS_* environment variables (eg LSS_NOTATION=hash).
+	The subdirectories of a recursive listing use the settings of the directory listed.
+	Use lss config show to see the settings in effect.
 	`
@@ -24,16 +24,10 @@ foo_bar.%04d.mb 1,3-4
 
 import (
 	"fmt"
-	"os"
-	"regexp"
 	"strconv"
 	"strings"
 )
 
-//import lg "lsscmd/lsslog"
-
-//var Logr = lg.NewDefaultLogger(lg.DEBUG)
-
 /*
 There are cases where lss cannot solve the problem
 foo.099.exr
@@ -55,6 +49,19 @@ const (
 	PADDED_FAIL           // if padding test fails
 )
 
+// String returns the name used for the Padding in log output.
+func (p Padding) String() string {
+	switch p {
+	case PADDED_NO:
+		return "unpadded"
+	case PADDED_YES:
+		return "padded"
+	case PADDED_EITHER:
+		return "either"
+	}
+	return "fail"
+}
+
 //---------------------------
 // Type DirItem
 //---------------------------
@@ -160,12 +167,8 @@ func NewDirItemFromSlice(pieces []string) *DirItem {
 // Returns:
 //     *DirItem - Pointer ot a DirItem struct instance.
 func NewDirItemFromString(item string) *DirItem {
-	results := re.FindAllStringSubmatch(item, -1)
-	if len(results) > 0 {
-		return NewDirItemFromSlice(results[0][1:])
-	} else {
-		return NewDirItem(item)
-	}
+	di, _ := ScanName(item)
+	return &di
 }
 
 //-------------------------
@@ -321,24 +324,3 @@ func DirItemsContiguous(lhs *DirItem, rhs *DirItem) bool {
 	}
 	return false
 }
-
-//-----------------------------------------
-// Private Utility Functions & Variables
-//-----------------------------------------
-
-// compileRegex
-//     Precompile the regular expression matching prefix, number, and extension
-//
-// Returns:
-//     *regexp.Regexp - pointer to the compiled regexp object
-func compileRegex() *regexp.Regexp {
-	var re, err = regexp.Compile("(.*)\\.([0-9]+)(\\..*){0,1}")
-
-	if err != nil {
-		fmt.Printf("ERROR compiling regex")
-		os.Exit(1)
-	}
-	return re
-}
-
-var re = compileRegex()
@@ -1,6 +1,7 @@
 package lss
 
 import (
+	"context"
 	"fmt"
 	"github.com/xlab/handysort"
 	"strconv"
@@ -71,7 +72,8 @@ func (dil DirItemList) String() string {
 // SortDirItemList - This function is designed to take a sorted
 // DirItemList and separate it into two lists - one for padded items
 // and one for non-padded items. The trick is that the non-padded items
-// are context dependent
+// are context dependent. The listing itself now uses GroupDirItems, which
+// resolves ambiguous items without depending upon their order.
 func SortDirItemList(list DirItemList) (DirItemList, DirItemList, int) {
 	maxlen := 0 // max length
 	clen := 0   // current length
@@ -134,14 +136,22 @@ func same(di1 *DirItem, di2 *DirItem) bool {
 // DivideByType filters a DirItemList into multiple DirItemLists,
 // each one representing a single range item. Each unique
 // DirItemList is returned to the channel provided as the return type.
+// The channel must be drained; use DivideByTypeCtx to stop early.
 func DivideByType(list DirItemList) chan DirItemList {
+	return DivideByTypeCtx(context.Background(), list)
+}
+
+// DivideByTypeCtx behaves as DivideByType, but stops publishing and closes
+// the channel once ctx is cancelled, so that callers may stop reading early
+// without leaking the publishing goroutine.
+func DivideByTypeCtx(ctx context.Context, list DirItemList) chan DirItemList {
 	// create empty DirItemSlice
 	ch := make(chan DirItemList)
 	go func() {
+		defer close(ch)
 		sz := len(list)
 		// if someone was a jerk and passed in an empty list
 		if sz == 0 {
-			close(ch)
 			return
 		}
 
@@ -158,7 +168,11 @@ func DivideByType(list DirItemList) chan DirItemList {
 			if same(&list[cnt], &list[cnt-1]) {
 				outslice = append(outslice, list[cnt])
 			} else {
-				ch <- outslice
+				select {
+				case ch <- outslice:
+				case <-ctx.Done():
+					return
+				}
 				outslice = NewDirItemListFromSlice([]string{})
 				outslice = append(outslice, list[cnt])
 			}
@@ -166,10 +180,11 @@ func DivideByType(list DirItemList) chan DirItemList {
 		}
 
 		if len(outslice) > 0 {
-			ch <- outslice
+			select {
+			case ch <- outslice:
+			case <-ctx.Done():
+			}
 		}
-
-		close(ch)
 	}()
 
 	return ch
@@ -231,8 +246,9 @@ func PadInt(x, padding int) string {
 // egs
 // PadToSize("foo",5,true) return "  foo"
 // PadToSize("foo",5,false) return "foo  "
+// Sizes are measured in terminal cells (see DisplayWidth), rather than bytes.
 func PadToSize(source string, toSize int, prefix bool) string {
-	sz := len(source)
+	sz := DisplayWidth(source)
 	padding := toSize - sz
 	if padding <= 0 {
 		return source
@@ -296,76 +312,46 @@ func BuildRangeString(list DirItemList, rangePadding int) string {
 	return rangestr
 }
 
-// RangeStringsFromSortedItemList takes a sorted DirItemList and returns a channel on which sorted strings are returned.
-// Your first question is probably why? Well, it does some internal kungfu to take that item list and reduce it
-// into its final output.
+// RangeStringsFromSortedItemList takes a DirItemList and returns a channel on which the
+// collapsed range strings are published, in order. The items are grouped by GroupDirItems, so
+// despite the name they no longer need to be sorted beforehand. The channel must be drained;
+// use RangeStringsFromSortedItemListCtx to stop early.
 func RangeStringsFromSortedItemList(itemList DirItemList) chan string {
+	return RangeStringsFromSortedItemListCtx(context.Background(), itemList)
+}
 
-	// sort DirItems into padded and nonPadded
-	padded, unpadded, maxlen := SortDirItemList(itemList)
-	paddedChan := DivideByType(padded)
-	unpaddedChan := DivideByType(unpadded)
-
-	ch := make(chan string)
-
-	go func() {
-		prBuff := make([]string, 0)  // padded Range buffer
-		uprBuff := make([]string, 0) // unpadded Range buffer
+// RangeStringsFromSortedItemListCtx behaves as RangeStringsFromSortedItemList, but stops
+// publishing and closes the channel once ctx is cancelled.
+func RangeStringsFromSortedItemListCtx(ctx context.Context, itemList DirItemList) chan string {
+	return publishStrings(ctx, RangeStringsFromSequences(GroupDirItems(itemList)))
+}
 
-		for {
+// RangesChanFromStringSlice collapses the supplied directory contents and returns a channel on
+// which the resulting range strings are published, in order. The channel must be drained; use
+// RangesChanFromStringSliceCtx to stop early.
+func RangesChanFromStringSlice(contents []string) chan string {
+	return RangesChanFromStringSliceCtx(context.Background(), contents)
+}
 
-			paddedRange, prOk := <-paddedChan
-			unpaddedRange, uprOk := <-unpaddedChan
+// RangesChanFromStringSliceCtx behaves as RangesChanFromStringSlice, but stops publishing and
+// closes the channel once ctx is cancelled.
+func RangesChanFromStringSliceCtx(ctx context.Context, contents []string) chan string {
+	return publishStrings(ctx, RangeStringsFromSequences(SequencesFromStringSlice(contents)))
+}
 
-			// if we fetched data from the channel
-			if prOk {
-				prBuff = append(prBuff, BuildRangeString(paddedRange, maxlen))
-			}
-			// if we fetched data from the channel
-			if uprOk {
-				uprBuff = append(uprBuff, BuildRangeString(unpaddedRange, maxlen))
-			}
-			// if both channels are empty lets beat it
-			if len(prBuff) == 0 && len(uprBuff) == 0 {
-				break
-			}
-			// ok, we move on.
-			switch {
-			case len(prBuff) > 0 && len(uprBuff) > 0:
-				// buffer entries are in the form
-				// count name range eg
-				// 2 foo.%04d.exr  4-5
-				// we use the fields command to split the string up into a slice
-				// and take the middle index (1)
-				if strings.Fields(prBuff[0])[1] < strings.Fields(uprBuff[0])[1] {
-					ch <- prBuff[0]
-					prBuff = prBuff[1:]
-				} else {
-					ch <- uprBuff[0]
-					uprBuff = uprBuff[1:]
-				}
-			case len(prBuff) > 0:
-				ch <- prBuff[0]
-				prBuff = prBuff[1:]
-			case len(uprBuff) > 0:
-				ch <- uprBuff[1]
-				uprBuff = uprBuff[1:]
+// publishStrings sends each of the supplied strings on the returned channel from a goroutine,
+// closing the channel once they have all been sent or ctx is cancelled.
+func publishStrings(ctx context.Context, values []string) chan string {
+	ch := make(chan string)
+	go func() {
+		defer close(ch)
+		for _, value := range values {
+			select {
+			case ch <- value:
+			case <-ctx.Done():
+				return
 			}
-
 		}
-		close(ch)
 	}()
-
 	return ch
 }
-
-func RangesChanFromStringSlice(contents []string) chan string {
-
-	// cast to a Stringlist and call NaturalSort()
-	Stringlist(contents).NaturalSort()
-
-	// build a dirItemList from the contents slice
-	dil := NewDirItemListFromSlice(contents)
-
-	return RangeStringsFromSortedItemList(dil)
-}
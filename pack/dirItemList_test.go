@@ -1,7 +1,11 @@
 package lss
 
 import (
+	"context"
+	"runtime"
+	"strconv"
 	"testing"
+	"time"
 )
 
 func TestDirItemList_NewFromSlice(t *testing.T) {
@@ -76,7 +80,7 @@ func TestDirItemList_SortDirItemList(t *testing.T) {
 				"Should Be:", sz, ".Number of Items:", il)
 		}
 
-		padded, unpadded := SortDirItemList(il)
+		padded, unpadded, _ := SortDirItemList(il)
 		pdil := NewSliceFromDirItemList(padded)
 		updil := NewSliceFromDirItemList(unpadded)
 		dil := append(pdil, updil...)
@@ -114,7 +118,7 @@ func TestDirItemList_BuildRangeString(t *testing.T) {
 			"Should Be:", sz, ".Number of Items:", il)
 	}
 
-	rs := BuildRangeString(il)
+	rs := BuildRangeString(il, 0)
 	println(rs)
 }
 
@@ -141,10 +145,54 @@ func TestDirItemList_DivideByType(t *testing.T) {
 	}
 
 	for x := range DivideByType(il) {
-		println(BuildRangeString(x))
+		println(BuildRangeString(x, 0))
 		/*for _, y := range x {
 			println(y.String())
 		}*/
 		println("")
 	}
 }
+
+// waitForGoroutines waits for the number of running goroutines to drop back to baseline,
+// failing the test if it does not do so promptly.
+func waitForGoroutines(t *testing.T, baseline int) {
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > baseline {
+		if time.Now().After(deadline) {
+			t.Fatal("leaked", runtime.NumGoroutine()-baseline, "goroutines")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestDirItemList_StreamsStopOnCancel(t *testing.T) {
+	contents := []string{}
+	for i := 0; i < 100; i++ {
+		contents = append(contents, "foo."+strconv.Itoa(i)+".exr", "bar"+strconv.Itoa(i)+".txt")
+	}
+	baseline := runtime.NumGoroutine()
+
+	for i := 0; i < 10; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		ranges := RangesChanFromStringSliceCtx(ctx, contents)
+		<-ranges
+		items := RangeStringsFromSortedItemListCtx(ctx, NewDirItemListFromSlice(contents))
+		<-items
+		groups := DivideByTypeCtx(ctx, NewDirItemListFromSlice(contents))
+		<-groups
+		cancel()
+	}
+	waitForGoroutines(t, baseline)
+}
+
+func TestDirItemList_StreamsCompleteWithoutCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cnt := 0
+	for range RangesChanFromStringSliceCtx(ctx, []string{"foo.0001.exr", "foo.0002.exr", "bar"}) {
+		cnt++
+	}
+	if cnt != 2 {
+		t.Error("Wrong number of ranges published:", cnt)
+	}
+}
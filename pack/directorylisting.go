@@ -2,47 +2,115 @@ package lss
 
 import (
 	"errors"
+	"io"
 	"os"
+	"path/filepath"
+	"time"
 )
 
+// LISTING_BATCH is the number of names read from a directory at a time, so that directories
+// holding millions of entries need never be held in memory as a single slice of names.
+const LISTING_BATCH = 4096
+
 // NewDirItemListFromPath returns an error object and a DirItemList
 func FilteredListingFromPath(path string, filter func(string) bool) (error, []string) {
 	vsf := make([]string, 0)
+	err := ReadDirNames(path, filter, func(name string) {
+		vsf = append(vsf, name)
+	})
+	return err, vsf
+}
+
+// ReadDirNames calls fn with each name within the directory path which the filter keeps,
+// reading the directory LISTING_BATCH names at a time. A nil filter keeps everything. Names
+// are supplied in directory order.
+func ReadDirNames(path string, filter func(string) bool, fn func(name string)) error {
 	dir, err := os.Open(path)
-	if err == nil {
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
 
-		defer func() {
-			if err := dir.Close(); err != nil {
-				panic(err)
-			}
-		}()
+	fileInfo, err := dir.Stat()
+	if err != nil {
+		return err
+	}
+	if !fileInfo.IsDir() {
+		return errors.New("Supplied path:'" + path + "' is not a directory")
+	}
 
-		fileInfo, err := dir.Stat()
-		if err != nil {
-			return err, vsf
+	debug := debugEnabled()
+	start := time.Now()
+	read, kept := 0, 0
+	for {
+		names, err := dir.Readdirnames(LISTING_BATCH)
+		for _, name := range names {
+			if filter == nil || filter(name) {
+				fn(name)
+				kept++
+			} else if debug {
+				logger.Debug("filtered out", "dir", path, "name", name)
+			}
 		}
-
-		if !fileInfo.IsDir() {
-			return errors.New("Supplied path:'" + path + "' is not a directory"), vsf
+		read += len(names)
+		if err == io.EOF {
+			logger.Info("read directory", "path", path, "entries", read, "kept", kept,
+				"elapsed", time.Since(start))
+			return nil
 		}
-
-		filenames, err := dir.Readdirnames(0)
-		if err == nil {
-
-			if filter == nil {
-				filter = func(nm string) bool { return true }
-			}
-			for _, v := range filenames {
-				if filter(v) {
-					vsf = append(vsf, v)
-				}
-			}
+		if err != nil {
+			return err
 		}
 	}
-	return err, vsf
+}
+
+// CollapsePath reads the directory path in batches, collapsing the names which the filter
+// keeps into Sequences as it goes. Unlike collapsing the result of FilteredListingFromPath,
+// the full listing is never held in memory, only the frame numbers of each sequence.
+func CollapsePath(path string, filter func(string) bool) (error, []Sequence) {
+	builder := NewSequenceBuilder()
+	if err := ReadDirNames(path, filter, builder.Add); err != nil {
+		return err, []Sequence{}
+	}
+	return nil, builder.Sequences()
 }
 
 func GetCwdPath() string {
 	path, _ := os.Getwd()
 	return path
 }
+
+// PathGroup holds the names of files, named individually on the command line, which share a
+// directory.
+type PathGroup struct {
+	Dir   string
+	Names []string
+}
+
+// ClassifyPaths splits command line arguments into directories, to be listed, and files,
+// to be collapsed together. Files are grouped by directory in order of first appearance, so
+// that a shell expanded glob such as shot*/foo.*.exr collapses into one sequence per shot.
+// Arguments which cannot be accessed are reported in errs and otherwise skipped.
+func ClassifyPaths(paths []string) (errs []error, dirs []string, files []PathGroup) {
+	groups := map[string]int{}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if info.IsDir() {
+			dirs = append(dirs, path)
+			continue
+		}
+		dir := filepath.Dir(path)
+		idx, ok := groups[dir]
+		if !ok {
+			idx = len(files)
+			groups[dir] = idx
+			files = append(files, PathGroup{Dir: dir})
+		}
+		files[idx].Names = append(files[idx].Names, filepath.Base(path))
+	}
+	return errs, dirs, files
+}
@@ -1,7 +1,10 @@
 package lss
 
 import (
+	"flag"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -66,3 +69,102 @@ func TestDirectorylisting_Filter(t *testing.T) {
 		t.Error("Wrong number of items returned:", cnt, ".Should be:", len(validlist))
 	}
 }
+
+func TestDirectorylisting_ClassifyPaths(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"shotA", "shotB"} {
+		os.Mkdir(filepath.Join(root, dir), 0755)
+		for _, name := range []string{"foo.0001.exr", "foo.0002.exr"} {
+			os.WriteFile(filepath.Join(root, dir, name), []byte{}, 0644)
+		}
+	}
+
+	errs, dirs, files := ClassifyPaths([]string{
+		filepath.Join(root, "shotA", "foo.0001.exr"),
+		filepath.Join(root, "shotB", "foo.0001.exr"),
+		filepath.Join(root, "shotA", "foo.0002.exr"),
+		filepath.Join(root, "shotB"),
+		filepath.Join(root, "missing"),
+	})
+	if len(errs) != 1 {
+		t.Error("Wrong number of errors:", errs)
+	}
+	if len(dirs) != 1 || dirs[0] != filepath.Join(root, "shotB") {
+		t.Error("Wrong directories:", dirs)
+	}
+	if len(files) != 2 {
+		t.Fatal("Wrong number of file groups:", files)
+	}
+	if files[0].Dir != filepath.Join(root, "shotA") ||
+		!testEq(files[0].Names, []string{"foo.0001.exr", "foo.0002.exr"}) {
+		t.Error("Wrong first group:", files[0])
+	}
+	if files[1].Dir != filepath.Join(root, "shotB") ||
+		!testEq(files[1].Names, []string{"foo.0001.exr"}) {
+		t.Error("Wrong second group:", files[1])
+	}
+}
+
+// largeBench enables the on disk benchmarks at 1M entries, which take some time to set up.
+var largeBench = flag.Bool("lss.large", false, "run the 1M entry on disk benchmarks")
+
+// makeListing creates a directory holding the supplied names as empty files.
+func makeListing(tb testing.TB, names []string) string {
+	dir := tb.TempDir()
+	for _, name := range names {
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			tb.Fatal(err)
+		}
+		f.Close()
+	}
+	return dir
+}
+
+func TestDirectorylisting_CollapsePath(t *testing.T) {
+	// span several batches, so that sequences are built across reads
+	names := benchNames(3*LISTING_BATCH + 7)
+	dir := makeListing(t, names)
+
+	err, got := CollapsePath(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := SequencesFromStringSlice(names); !reflect.DeepEqual(got, want) {
+		t.Error("CollapsePath was", got, "Should Be:", want)
+	}
+
+	err, listing := FilteredListingFromPath(dir, nil)
+	if err != nil || len(listing) != len(names) {
+		t.Error("FilteredListingFromPath returned", len(listing), "names. Should Be:", len(names), err)
+	}
+
+	err, _ = CollapsePath(filepath.Join(dir, names[0]), nil)
+	if err == nil {
+		t.Error("CollapsePath of a file should fail")
+	}
+}
+
+// benchmarkCollapsePath times listing, collapsing and formatting a directory of n entries.
+// See the README for the wall time and allocation targets.
+func benchmarkCollapsePath(b *testing.B, n int) {
+	dir := makeListing(b, benchNames(n))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err, seqs := CollapsePath(dir, HiddenFilter(false))
+		if err != nil {
+			b.Fatal(err)
+		}
+		RangeStringsFromSequences(seqs)
+	}
+}
+
+func BenchmarkDirectorylisting_CollapsePath10k(b *testing.B)  { benchmarkCollapsePath(b, 10000) }
+func BenchmarkDirectorylisting_CollapsePath100k(b *testing.B) { benchmarkCollapsePath(b, 100000) }
+func BenchmarkDirectorylisting_CollapsePath1M(b *testing.B) {
+	if !*largeBench {
+		b.Skip("pass -lss.large to run")
+	}
+	benchmarkCollapsePath(b, 1000000)
+}
@@ -0,0 +1,229 @@
+package lss
+
+/*
+filter provides the Filter type, used to decide which directory entries are listed. Filters
+are plain functions, so that they may be handed straight to FilteredListingFromPath, and may
+be combined using And, Or and Not:
+
+	err, exr := GlobFilter("*.exr")
+	filter := HiddenFilter(false).And(exr).And(SingleFilter().Not())
+
+FilterOptions gathers the filters exposed on the command line into a single chain.
+*/
+
+import (
+	"errors"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+//-------------------------
+// Type Filter
+//-------------------------
+
+// Filter returns true if the supplied directory entry name should be kept. A nil Filter
+// keeps everything.
+type Filter func(name string) bool
+
+// Keep calls the Filter, treating a nil Filter as one which keeps everything.
+func (f Filter) Keep(name string) bool {
+	return f == nil || f(name)
+}
+
+// And returns a Filter keeping names kept by both f and other.
+func (f Filter) And(other Filter) Filter {
+	return func(name string) bool {
+		return f.Keep(name) && other.Keep(name)
+	}
+}
+
+// Or returns a Filter keeping names kept by either f or other.
+func (f Filter) Or(other Filter) Filter {
+	return func(name string) bool {
+		return f.Keep(name) || other.Keep(name)
+	}
+}
+
+// Not returns a Filter keeping the names f rejects.
+func (f Filter) Not() Filter {
+	return func(name string) bool {
+		return !f.Keep(name)
+	}
+}
+
+//-------------------------
+// Filter Constructors
+//-------------------------
+
+// HiddenFilter returns a Filter rejecting hidden (dot) files, unless showHidden is true.
+func HiddenFilter(showHidden bool) Filter {
+	return func(name string) bool {
+		return showHidden || len(name) == 0 || name[0] != '.'
+	}
+}
+
+// GlobFilter returns a Filter keeping names matching the supplied shell glob
+// (see path/filepath.Match).
+func GlobFilter(pattern string) (error, Filter) {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return errors.New("bad glob '" + pattern + "': " + err.Error()), nil
+	}
+	return nil, func(name string) bool {
+		matched, _ := filepath.Match(pattern, name)
+		return matched
+	}
+}
+
+// RegexFilter returns a Filter keeping names matched by the supplied regular expression.
+// The expression is unanchored; use ^ and $ to match whole names.
+func RegexFilter(expr string) (error, Filter) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return errors.New("bad regular expression '" + expr + "': " + err.Error()), nil
+	}
+	return nil, re.MatchString
+}
+
+// ExtFilter returns a Filter keeping names ending in one of the supplied extensions, which
+// may be given with or without a leading "." (eg "exr" or ".exr"). Matching ignores case.
+func ExtFilter(exts ...string) Filter {
+	suffixes := make([]string, 0, len(exts))
+	for _, ext := range exts {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" {
+			continue
+		}
+		if ext[0] != '.' {
+			ext = "." + ext
+		}
+		suffixes = append(suffixes, ext)
+	}
+	return func(name string) bool {
+		lower := strings.ToLower(name)
+		for _, suffix := range suffixes {
+			if strings.HasSuffix(lower, suffix) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// SequenceFilter returns a Filter keeping names which carry a frame number, and so
+// collapse into sequences.
+func SequenceFilter() Filter {
+	return func(name string) bool {
+		di := NewDirItemFromString(name)
+		return di.Number >= 0 && di.String() == name
+	}
+}
+
+// SingleFilter returns a Filter keeping names which do not carry a frame number.
+func SingleFilter() Filter {
+	return SequenceFilter().Not()
+}
+
+//-------------------------
+// Type FilterOptions
+//-------------------------
+
+// FilterOptions describes a chain of filters, applied in order to each name before it is
+// collapsed. A name is kept if it is not hidden (unless ShowHidden), matches at least one
+// of the Include globs and IncludeRegex expressions (if there are any), matches none of the
+// Exclude globs and ExcludeRegex expressions, ends in one of the Extensions (if there are
+// any), and is a sequence member or a single file as requested by OnlySequences and
+// OnlySingles.
+type FilterOptions struct {
+	ShowHidden    bool
+	Include       []string
+	Exclude       []string
+	IncludeRegex  []string
+	ExcludeRegex  []string
+	Extensions    []string
+	OnlySequences bool
+	OnlySingles   bool
+}
+
+// Filter builds the Filter described by the FilterOptions, returning an error if any of
+// the globs or regular expressions are malformed, or if the options contradict each other.
+func (o *FilterOptions) Filter() (error, Filter) {
+	if o.OnlySequences && o.OnlySingles {
+		return errors.New("only sequences and only singles are mutually exclusive"), nil
+	}
+
+	chain := HiddenFilter(o.ShowHidden)
+
+	var includes Filter
+	for _, pattern := range o.Include {
+		err, f := GlobFilter(pattern)
+		if err != nil {
+			return err, nil
+		}
+		includes = orFilter(includes, f)
+	}
+	for _, expr := range o.IncludeRegex {
+		err, f := RegexFilter(expr)
+		if err != nil {
+			return err, nil
+		}
+		includes = orFilter(includes, f)
+	}
+	if includes != nil {
+		chain = chain.And(includes)
+	}
+
+	for _, pattern := range o.Exclude {
+		err, f := GlobFilter(pattern)
+		if err != nil {
+			return err, nil
+		}
+		chain = chain.And(f.Not())
+	}
+	for _, expr := range o.ExcludeRegex {
+		err, f := RegexFilter(expr)
+		if err != nil {
+			return err, nil
+		}
+		chain = chain.And(f.Not())
+	}
+
+	if exts := splitList(o.Extensions); len(exts) > 0 {
+		chain = chain.And(ExtFilter(exts...))
+	}
+
+	switch {
+	case o.OnlySequences:
+		chain = chain.And(SequenceFilter())
+	case o.OnlySingles:
+		chain = chain.And(SingleFilter())
+	}
+	return nil, chain
+}
+
+//-----------------------------------------
+// Private Utility Functions
+//-----------------------------------------
+
+// orFilter ors f onto a possibly nil Filter, such that a nil lhs keeps nothing rather than
+// everything.
+func orFilter(lhs Filter, f Filter) Filter {
+	if lhs == nil {
+		return f
+	}
+	return lhs.Or(f)
+}
+
+// splitList splits each of the supplied values on commas, so that lists may be given
+// either as repeated values or as a single comma separated value (eg "exr,dpx").
+func splitList(values []string) []string {
+	ret := []string{}
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				ret = append(ret, item)
+			}
+		}
+	}
+	return ret
+}
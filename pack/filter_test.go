@@ -0,0 +1,92 @@
+package lss
+
+import (
+	"testing"
+)
+
+var filterTestNames = []string{
+	".hidden",
+	"foo.0001.exr",
+	"foo.0002.exr",
+	"foo.0001.dpx",
+	"bar.1.EXR",
+	"bar.tmp",
+	"readme.txt",
+}
+
+func applyFilter(f Filter, names []string) []string {
+	kept := []string{}
+	for _, name := range names {
+		if f.Keep(name) {
+			kept = append(kept, name)
+		}
+	}
+	return kept
+}
+
+func TestFilter_Combinators(t *testing.T) {
+	err, exr := GlobFilter("*.exr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err, foo := RegexFilter("^foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		filter   Filter
+		expected []string
+	}{
+		"and": {exr.And(foo), []string{"foo.0001.exr", "foo.0002.exr"}},
+		"or":  {exr.Or(foo), []string{"foo.0001.exr", "foo.0002.exr", "foo.0001.dpx"}},
+		"not": {foo.Not().And(HiddenFilter(false)), []string{"bar.1.EXR", "bar.tmp", "readme.txt"}},
+		"nil": {nil, filterTestNames},
+	}
+	for name, test := range tests {
+		if kept := applyFilter(test.filter, filterTestNames); !testEq(kept, test.expected) {
+			t.Error(name, "kept", kept, "Should Be:", test.expected)
+		}
+	}
+}
+
+func TestFilter_BadPatterns(t *testing.T) {
+	if err, _ := GlobFilter("foo[.exr"); err == nil {
+		t.Error("GlobFilter accepted a malformed glob")
+	}
+	if err, _ := RegexFilter("foo(.exr"); err == nil {
+		t.Error("RegexFilter accepted a malformed expression")
+	}
+}
+
+func TestFilter_Options(t *testing.T) {
+	tests := []struct {
+		options  FilterOptions
+		expected []string
+	}{
+		{FilterOptions{},
+			[]string{"foo.0001.exr", "foo.0002.exr", "foo.0001.dpx", "bar.1.EXR", "bar.tmp", "readme.txt"}},
+		{FilterOptions{ShowHidden: true, OnlySingles: true},
+			[]string{".hidden", "bar.tmp", "readme.txt"}},
+		{FilterOptions{Extensions: []string{"exr,.dpx"}},
+			[]string{"foo.0001.exr", "foo.0002.exr", "foo.0001.dpx", "bar.1.EXR"}},
+		{FilterOptions{Exclude: []string{"*.tmp"}, ExcludeRegex: []string{`\.dpx$`}, OnlySingles: true},
+			[]string{"readme.txt"}},
+		{FilterOptions{Include: []string{"bar.*"}, IncludeRegex: []string{"0002"}, OnlySequences: true},
+			[]string{"foo.0002.exr", "bar.1.EXR"}},
+	}
+	for i, test := range tests {
+		err, f := test.options.Filter()
+		if err != nil {
+			t.Fatal(i, err)
+		}
+		if kept := applyFilter(f, filterTestNames); !testEq(kept, test.expected) {
+			t.Error(i, "kept", kept, "Should Be:", test.expected)
+		}
+	}
+
+	contradictory := FilterOptions{OnlySequences: true, OnlySingles: true}
+	if err, _ := contradictory.Filter(); err == nil {
+		t.Error("contradictory options were accepted")
+	}
+}
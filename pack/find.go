@@ -0,0 +1,232 @@
+package lss
+
+/*
+find provides the predicates used by lss find to pick collapsed Sequences out of a tree, eg
+every EXR sequence under a shot with fewer than 100 frames, or every sequence modified within
+the last day.
+
+Numeric predicates are written as Comparisons, in the manner of find(1):
+
++N - more than N
+-N - fewer than N
+N  - exactly N
+
+Sizes may carry a unit of K, M, G or T (binary multiples, eg +1G), and ages a unit of s, m,
+h, d or w (eg -1d, modified within the last day). Ages without a unit are in days. As in
+find(1), sizes and ages are compared in whole units, sizes rounded up and ages down, so that
+1M matches sizes of up to a megabyte, and 1 (day) ages of between one and two days.
+*/
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SIZE_UNITS are the units a size Comparison may carry, in bytes.
+var SIZE_UNITS = map[string]int64{"": 1, "B": 1, "K": 1 << 10, "M": 1 << 20, "G": 1 << 30, "T": 1 << 40}
+
+// AGE_UNITS are the units an age Comparison may carry, in seconds.
+var AGE_UNITS = map[string]int64{"": 86400, "s": 1, "m": 60, "h": 3600, "d": 86400, "w": 7 * 86400}
+
+//-------------------------
+// Type Comparison
+//-------------------------
+
+// Comparison is a predicate on a number. The zero Comparison matches every number.
+type Comparison struct {
+	Op    byte  // '>', '<' or '=', or 0 to match anything
+	Value int64 // the number compared against
+	Unit  int64 // the unit the number was given in, eg 86400 for days
+}
+
+// ParseComparison parses s, in the +N, -N or N form described above. N may be followed by
+// one of units, which scales it; a nil units accepts plain numbers only.
+func ParseComparison(s string, units map[string]int64) (error, Comparison) {
+	cmp := Comparison{Op: '='}
+	text := s
+	switch {
+	case strings.HasPrefix(text, "+"):
+		cmp.Op, text = '>', text[1:]
+	case strings.HasPrefix(text, "-"):
+		cmp.Op, text = '<', text[1:]
+	}
+	scale := int64(1)
+	digits := strings.TrimRightFunc(text, func(r rune) bool { return r < '0' || r > '9' })
+	if unit := text[len(digits):]; unit != "" || units != nil {
+		var ok bool
+		if scale, ok = units[unit]; !ok {
+			return errors.New("unknown unit '" + unit + "' in '" + s + "'"), Comparison{}
+		}
+	}
+	value, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return errors.New("expected +N, -N or N, not '" + s + "'"), Comparison{}
+	}
+	cmp.Value, cmp.Unit = value*scale, scale
+	return nil, cmp
+}
+
+// Matches returns true if n satisfies the Comparison.
+func (c Comparison) Matches(n int64) bool {
+	switch c.Op {
+	case '>':
+		return n > c.Value
+	case '<':
+		return n < c.Value
+	case '=':
+		return n == c.Value
+	}
+	return true
+}
+
+// MatchesUnits returns true if n, rounded to a whole number of the Comparison's unit (up, or
+// else down), satisfies the Comparison.
+func (c Comparison) MatchesUnits(n int64, up bool) bool {
+	if c.Unit > 1 {
+		rest := n % c.Unit
+		n -= rest
+		switch {
+		case up && rest > 0:
+			n += c.Unit
+		case !up && rest < 0:
+			n -= c.Unit
+		}
+	}
+	return c.Matches(n)
+}
+
+//-------------------------
+// Type FindQuery
+//-------------------------
+
+// Whether a FindQuery selects sequences with or without missing frames.
+const (
+	GAPS_ANY  = iota // either
+	GAPS_ONLY        // only sequences with missing frames
+	GAPS_NONE        // only sequences without missing frames
+)
+
+// FindQuery selects Sequences by the predicates it holds. Every predicate must be satisfied
+// for a Sequence to match; the zero FindQuery matches every sequence, but no single files.
+type FindQuery struct {
+	Prefix  string     // glob matched against the prefix of a sequence, or the name of a single file
+	Padding Comparison // width of the frame numbers; 1 for unpadded sequences
+	Count   Comparison // number of members
+	First   Comparison // first frame
+	Last    Comparison // last frame
+	Gaps    int        // GAPS_ANY, GAPS_ONLY or GAPS_NONE
+	Size    Comparison // total size of the members, in bytes
+	Age     Comparison // age of the most recently modified member, in seconds
+	Singles bool       // whether single files may match
+}
+
+// Check returns an error if the query is malformed.
+func (q *FindQuery) Check() error {
+	if _, err := filepath.Match(q.Prefix, ""); err != nil {
+		return errors.New("bad glob '" + q.Prefix + "': " + err.Error())
+	}
+	return nil
+}
+
+// NeedsStat returns true if matching against the query requires the members to be stat-ed.
+func (q *FindQuery) NeedsStat() bool {
+	return q.Size.Op != 0 || q.Age.Op != 0
+}
+
+// Matches returns true if seq, found within dir, satisfies the query at the time now. The
+// members are only stat-ed if the query needs it, and the stat is returned for reuse.
+func (q *FindQuery) Matches(dir string, seq *Sequence, now time.Time) (error, bool, *SequenceStat) {
+	if seq.Single() {
+		if !q.Singles {
+			return nil, false, nil
+		}
+		// directories are listed as single entries, but are not files
+		if info, err := os.Stat(filepath.Join(dir, seq.Prefix)); err == nil && info.IsDir() {
+			return nil, false, nil
+		}
+	}
+	if q.Prefix != "" {
+		if matched, _ := filepath.Match(q.Prefix, seq.Prefix); !matched {
+			return nil, false, nil
+		}
+	}
+	padding := int64(seq.Padding)
+	if padding == 0 {
+		padding = 1
+	}
+	if !q.Padding.Matches(padding) || !q.Count.Matches(int64(seq.Len())) ||
+		!q.First.Matches(int64(seq.First())) || !q.Last.Matches(int64(seq.Last())) {
+		return nil, false, nil
+	}
+	if (q.Gaps == GAPS_ONLY && !seq.HasGaps()) || (q.Gaps == GAPS_NONE && seq.HasGaps()) {
+		return nil, false, nil
+	}
+	if !q.NeedsStat() {
+		return nil, true, nil
+	}
+	err, stat := StatSequence(dir, seq)
+	if err != nil {
+		return err, false, nil
+	}
+	age := int64(now.Sub(stat.ModTime) / time.Second)
+	return nil, q.Size.MatchesUnits(stat.Size, true) && q.Age.MatchesUnits(age, false), &stat
+}
+
+//-------------------------
+// Type SequenceRecord
+//-------------------------
+
+// SequenceRecord describes a Sequence found on disk, for JSON output.
+type SequenceRecord struct {
+	Dir     string    `json:"dir"`
+	Pattern string    `json:"pattern"`
+	Prefix  string    `json:"prefix"`
+	Padding int       `json:"padding"`
+	Ext     string    `json:"ext"`
+	First   int       `json:"first"`
+	Last    int       `json:"last"`
+	Count   int       `json:"count"`
+	Ranges  string    `json:"ranges"`
+	Missing string    `json:"missing,omitempty"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mtime"`
+
+	// set only when a Rate is given
+	FPS        float64 `json:"fps,omitempty"`
+	FirstTC    string  `json:"first_tc,omitempty"`
+	LastTC     string  `json:"last_tc,omitempty"`
+	Duration   float64 `json:"duration,omitempty"` // seconds from the first frame to the end of the last
+	DurationTC string  `json:"duration_tc,omitempty"`
+}
+
+// NewSequenceRecord returns the SequenceRecord of seq, found within dir, with its pattern and
+// frames written in style, and its timecodes and duration at rate, unless rate's FPS is 0.
+func NewSequenceRecord(dir string, seq *Sequence, style Style, stat SequenceStat, rate Rate) SequenceRecord {
+	record := SequenceRecord{
+		Dir:     dir,
+		Pattern: style.Pattern(seq),
+		Prefix:  seq.Prefix,
+		Padding: seq.Padding,
+		Ext:     seq.Extension,
+		First:   seq.First(),
+		Last:    seq.Last(),
+		Count:   seq.Len(),
+		Ranges:  style.Ranges(seq.Frames),
+		Missing: style.Ranges(seq.Missing()),
+		Size:    stat.Size,
+		ModTime: stat.ModTime,
+	}
+	if rate.FPS > 0 && !seq.Single() {
+		frames := seq.Last() - seq.First() + 1
+		record.FPS = rate.FPS
+		record.FirstTC = FrameToTimecode(seq.First(), rate)
+		record.LastTC = FrameToTimecode(seq.Last(), rate)
+		record.Duration = Duration(frames, rate)
+		record.DurationTC = FrameToTimecode(frames, rate)
+	}
+	return record
+}
@@ -0,0 +1,187 @@
+package lss
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFind_ParseComparison(t *testing.T) {
+	tests := []struct {
+		text     string
+		units    map[string]int64
+		expected Comparison
+	}{
+		{"100", nil, Comparison{'=', 100, 1}},
+		{"+1000", nil, Comparison{'>', 1000, 1}},
+		{"-100", nil, Comparison{'<', 100, 1}},
+		{"+1G", SIZE_UNITS, Comparison{'>', 1 << 30, 1 << 30}},
+		{"512", SIZE_UNITS, Comparison{'=', 512, 1}},
+		{"-1d", AGE_UNITS, Comparison{'<', 86400, 86400}},
+		{"-2", AGE_UNITS, Comparison{'<', 2 * 86400, 86400}},
+		{"+90m", AGE_UNITS, Comparison{'>', 90 * 60, 60}},
+	}
+	for _, test := range tests {
+		err, got := ParseComparison(test.text, test.units)
+		if err != nil || got != test.expected {
+			t.Errorf("ParseComparison(%q) returned %v, %v Should Be: %v", test.text, got, err, test.expected)
+		}
+	}
+	for _, bad := range []string{"", "+", "ten", "10x", "1.5G"} {
+		if err, _ := ParseComparison(bad, SIZE_UNITS); err == nil {
+			t.Errorf("ParseComparison(%q) did not fail", bad)
+		}
+	}
+	if err, _ := ParseComparison("10K", nil); err == nil {
+		t.Error("ParseComparison accepted a unit where none are allowed")
+	}
+}
+
+func TestFind_MatchesUnits(t *testing.T) {
+	tests := []struct {
+		text  string
+		units map[string]int64
+		up    bool
+		n     int64
+		match bool
+	}{
+		{"1M", SIZE_UNITS, true, 1 << 20, true},
+		{"1M", SIZE_UNITS, true, 1000, true},
+		{"1M", SIZE_UNITS, true, 1<<20 + 1, false},
+		{"+1M", SIZE_UNITS, true, 1<<20 + 1, true},
+		{"1", AGE_UNITS, false, 86400, true},
+		{"1", AGE_UNITS, false, 2*86400 - 1, true},
+		{"1", AGE_UNITS, false, 86399, false},
+		{"-1", AGE_UNITS, false, 86399, true},
+		{"+1", AGE_UNITS, false, 2*86400 - 1, false},
+		{"0", AGE_UNITS, false, -10, false},
+		{"100", nil, false, 100, true},
+	}
+	for _, test := range tests {
+		_, cmp := ParseComparison(test.text, test.units)
+		if cmp.MatchesUnits(test.n, test.up) != test.match {
+			t.Errorf("%s matched %d: %v Should Be: %v", test.text, test.n, !test.match, test.match)
+		}
+	}
+}
+
+func TestFind_Matches(t *testing.T) {
+	seqs := SequencesFromStringSlice([]string{
+		"beauty.0001.exr", "beauty.0002.exr", "beauty.0003.exr",
+		"bg.1001.dpx", "bg.1003.dpx",
+		"matte.1.exr", "matte.2.exr",
+		"notes.txt",
+	})
+	tests := []struct {
+		name     string
+		query    FindQuery
+		expected []string
+	}{
+		{"zero", FindQuery{}, []string{"beauty", "bg", "matte"}},
+		{"singles", FindQuery{Singles: true}, []string{"beauty", "bg", "matte", "notes.txt"}},
+		{"prefix", FindQuery{Prefix: "b*"}, []string{"beauty", "bg"}},
+		{"padding", FindQuery{Padding: Comparison{'=', 1, 1}}, []string{"matte"}},
+		{"count", FindQuery{Count: Comparison{'<', 3, 1}}, []string{"bg", "matte"}},
+		{"first", FindQuery{First: Comparison{'>', 1000, 1}}, []string{"bg"}},
+		{"last", FindQuery{Last: Comparison{'=', 3, 1}}, []string{"beauty"}},
+		{"gaps", FindQuery{Gaps: GAPS_ONLY}, []string{"bg"}},
+		{"no gaps", FindQuery{Gaps: GAPS_NONE}, []string{"beauty", "matte"}},
+	}
+	for _, test := range tests {
+		got := []string{}
+		for i := range seqs {
+			err, matched, stat := test.query.Matches(".", &seqs[i], time.Now())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stat != nil {
+				t.Errorf("%s: stat-ed %s needlessly", test.name, seqs[i].Prefix)
+			}
+			if matched {
+				got = append(got, seqs[i].Prefix)
+			}
+		}
+		if !testEq(got, test.expected) {
+			t.Errorf("%s matched %v Should Be: %v", test.name, got, test.expected)
+		}
+	}
+}
+
+func TestFind_MatchesStat(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"old.0001.exr", "old.0002.exr", "new.0001.exr"} {
+		os.WriteFile(filepath.Join(dir, name), make([]byte, 2048), 0644)
+	}
+	old := time.Now().Add(-48 * time.Hour)
+	os.Chtimes(filepath.Join(dir, "old.0001.exr"), old, old)
+	os.Chtimes(filepath.Join(dir, "old.0002.exr"), old, old)
+	err, seqs := CollapsePath(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string][]string{
+		"-1d":  {"new"},
+		"+1d":  {"old"},
+		"-3d":  {"new", "old"},
+		"+47h": {"old"},
+	}
+	for age, expected := range tests {
+		_, cmp := ParseComparison(age, AGE_UNITS)
+		query := FindQuery{Age: cmp}
+		got := []string{}
+		for i := range seqs {
+			err, matched, stat := query.Matches(dir, &seqs[i], time.Now())
+			if err != nil || stat == nil {
+				t.Fatal("Matches by age failed to stat:", err)
+			}
+			if matched {
+				got = append(got, seqs[i].Prefix)
+			}
+		}
+		if !testEq(got, expected) {
+			t.Errorf("age %s matched %v Should Be: %v", age, got, expected)
+		}
+	}
+
+	query := FindQuery{Size: Comparison{'>', 3 << 10, 1 << 10}}
+	for i := range seqs {
+		_, matched, _ := query.Matches(dir, &seqs[i], time.Now())
+		if matched != (seqs[i].Prefix == "old") {
+			t.Errorf("size +3K matched %s: %v", seqs[i].Prefix, matched)
+		}
+	}
+}
+
+func TestFind_MatchesSingles(t *testing.T) {
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, "sub"), 0755)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte{}, 0644)
+	err, seqs := CollapsePath(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	singles := []string{}
+	for i := range seqs {
+		if _, matched, _ := (&FindQuery{Singles: true}).Matches(dir, &seqs[i], time.Now()); matched && seqs[i].Single() {
+			singles = append(singles, seqs[i].Prefix)
+		}
+	}
+	if !testEq(singles, []string{"notes.txt"}) {
+		t.Error("singles matched", singles, "Should Be: [notes.txt]")
+	}
+}
+
+func TestFind_SequenceRecordRate(t *testing.T) {
+	seq := SequencesFromStringSlice([]string{"foo.1001.exr", "foo.1002.exr", "foo.1048.exr"})[0]
+	record := NewSequenceRecord(".", &seq, Style{}, SequenceStat{}, Rate{})
+	if record.FPS != 0 || record.FirstTC != "" || record.Duration != 0 {
+		t.Error("record without a rate carried timecodes:", record)
+	}
+	_, rate := ParseRate("24")
+	record = NewSequenceRecord(".", &seq, Style{}, SequenceStat{}, rate)
+	if record.FirstTC != "00:00:41:17" || record.LastTC != "00:00:43:16" || record.Duration != 2 || record.DurationTC != "00:00:02:00" {
+		t.Error("record at 24 fps was", record)
+	}
+}
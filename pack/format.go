@@ -0,0 +1,305 @@
+package lss
+
+/*
+format renders collapsed listings for display. Columns are aligned by the number of terminal
+cells each string actually occupies (see DisplayWidth), rather than by its length in bytes, so
+that names holding multibyte or wide characters line up.
+
+Names may be colored by Kind, using ANSI SGR sequences configured in the manner of LS_COLORS:
+a colon separated list of key=value pairs, eg sq=01;32:gp=01;33:fi=0:di=01;34, where the keys
+are
+
+sq - a sequence without gaps
+gp - a sequence with missing frames
+fi - a single file
+di - a directory
+
+Colors are only applied to the names themselves, never to the padding which follows them, so
+that colored and plain listings align identically.
+*/
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+const (
+	COUNT_WIDTH    = 5 // minimum width of the count column
+	COLUMN_SPACING = 2 // spaces between the columns of Formatter.Columns
+)
+
+//-------------------------
+// Type Kind
+//-------------------------
+
+// Kind distinguishes the entries of a listing for coloring.
+type Kind int
+
+const (
+	KIND_SEQUENCE  Kind = iota // a sequence without gaps
+	KIND_GAPS                  // a sequence with missing frames
+	KIND_SINGLE                // a single file
+	KIND_DIRECTORY             // a directory
+)
+
+// colorKeys maps the keys of a color specification to Kinds.
+var colorKeys = map[string]Kind{
+	"sq": KIND_SEQUENCE,
+	"gp": KIND_GAPS,
+	"fi": KIND_SINGLE,
+	"di": KIND_DIRECTORY,
+}
+
+//-------------------------
+// Type Colors
+//-------------------------
+
+// Colors holds the SGR parameters (eg "01;34") used to paint each Kind. Kinds without an
+// entry, or with an empty one, are left unpainted.
+type Colors map[Kind]string
+
+// DefaultColors returns the colors used when LSS_COLORS is not set.
+func DefaultColors() Colors {
+	return Colors{
+		KIND_SEQUENCE:  "01;32",
+		KIND_GAPS:      "01;33",
+		KIND_SINGLE:    "",
+		KIND_DIRECTORY: "01;34",
+	}
+}
+
+// ParseColors returns DefaultColors, updated by the colon separated key=value pairs in spec
+// (see above). Unknown keys are ignored, as ls ignores them, so that one specification may
+// serve several versions of lss.
+func ParseColors(spec string) (error, Colors) {
+	colors := DefaultColors()
+	for _, field := range strings.Split(spec, ":") {
+		if field == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return errors.New("invalid color:'" + field + "' (expected key=value)"), nil
+		}
+		for _, r := range value {
+			if r != ';' && (r < '0' || r > '9') {
+				return errors.New("invalid color:'" + field + "' (expected SGR parameters, eg 01;34)"), nil
+			}
+		}
+		if kind, ok := colorKeys[key]; ok {
+			colors[kind] = value
+		}
+	}
+	return nil, colors
+}
+
+// Paint wraps s in the escape sequences which color it as kind. A nil Colors paints nothing.
+func (c Colors) Paint(kind Kind, s string) string {
+	sgr := c[kind]
+	if sgr == "" || sgr == "0" || sgr == "00" {
+		return s
+	}
+	return "\033[" + sgr + "m" + s + "\033[0m"
+}
+
+//-------------------------
+// Type Formatter
+//-------------------------
+
+// Formatter renders Sequences as aligned lines of text.
+type Formatter struct {
+	Style  Style                  // how patterns and frames are written
+	Colors Colors                 // colors to paint names with, or nil for plain output
+	IsDir  func(name string) bool // reports whether a single file is a directory, or nil
+	Rate   Rate                   // adds timecode columns to Lines, unless its FPS is 0
+}
+
+// Kind returns the Kind of seq.
+func (f *Formatter) Kind(seq *Sequence) Kind {
+	switch {
+	case seq.Single() || seq.Len() == 1:
+		if f.IsDir != nil && f.IsDir(seq.Names()[0]) {
+			return KIND_DIRECTORY
+		}
+		return KIND_SINGLE
+	case seq.HasGaps():
+		return KIND_GAPS
+	}
+	return KIND_SEQUENCE
+}
+
+// Lines renders each of seqs on a line of its own, in the same form as
+// Sequence.RangeString: the count, then the pattern, then the frames. The columns are as wide
+// as their widest entry. If the Formatter has a Rate, the timecodes of the first and last
+// frames follow, and then the duration, as a timecode, from the first frame to the last. The
+// returned slice is parallel to seqs.
+func (f *Formatter) Lines(seqs []Sequence) []string {
+	countWidth, nameWidth, rangesWidth := COUNT_WIDTH, 0, 0
+	for i := range seqs {
+		if digits := NumDigits(seqs[i].Len()); digits > countWidth {
+			countWidth = digits
+		}
+		if seqs[i].Len() > 1 {
+			if width := DisplayWidth(f.Style.Pattern(&seqs[i])); width > nameWidth {
+				nameWidth = width
+			}
+			if width := DisplayWidth(f.Style.Ranges(seqs[i].Frames)); width > rangesWidth {
+				rangesWidth = width
+			}
+		}
+	}
+
+	lines := make([]string, len(seqs))
+	for i := range seqs {
+		seq := &seqs[i]
+		kind := f.Kind(seq)
+		line := PadInt(seq.Len(), countWidth) + " "
+		if seq.Len() == 1 {
+			lines[i] = line + f.Colors.Paint(kind, seq.Names()[0])
+			continue
+		}
+		pattern, ranges := f.Style.Pattern(seq), f.Style.Ranges(seq.Frames)
+		lines[i] = line + f.Colors.Paint(kind, pattern) + padding(nameWidth-DisplayWidth(pattern)) +
+			"    " + ranges
+		if f.Rate.FPS > 0 {
+			lines[i] += padding(rangesWidth-DisplayWidth(ranges)) + "    " + FrameToTimecode(seq.First(), f.Rate) +
+				"  " + FrameToTimecode(seq.Last(), f.Rate) + "  " + FrameToTimecode(seq.Last()-seq.First()+1, f.Rate)
+		}
+	}
+	return lines
+}
+
+// Columns renders seqs in the manner of ls -C: each as its pattern and frames (or its name),
+// laid out down as many columns as fit within width terminal cells.
+func (f *Formatter) Columns(seqs []Sequence, width int) []string {
+	cells := make([]string, len(seqs))
+	widths := make([]int, len(seqs))
+	for i := range seqs {
+		seq := &seqs[i]
+		kind := f.Kind(seq)
+		if seq.Len() == 1 {
+			name := seq.Names()[0]
+			cells[i], widths[i] = f.Colors.Paint(kind, name), DisplayWidth(name)
+			continue
+		}
+		pattern, ranges := f.Style.Pattern(seq), f.Style.Ranges(seq.Frames)
+		cells[i] = f.Colors.Paint(kind, pattern) + " " + ranges
+		widths[i] = DisplayWidth(pattern) + 1 + DisplayWidth(ranges)
+	}
+
+	rows, columnWidths := columnLayout(widths, width)
+	lines := make([]string, rows)
+	for row := 0; row < rows; row++ {
+		line := ""
+		for col := range columnWidths {
+			i := col*rows + row
+			if i >= len(cells) {
+				break
+			}
+			line += cells[i]
+			if next := i + rows; next < len(cells) {
+				line += padding(columnWidths[col] - widths[i] + COLUMN_SPACING)
+			}
+		}
+		lines[row] = line
+	}
+	return lines
+}
+
+//-------------------------
+// Width Functions
+//-------------------------
+
+// DisplayWidth returns the number of terminal cells s occupies: combining marks and control
+// characters occupy none, and East Asian wide characters occupy two.
+func DisplayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		switch {
+		case r >= 0x20 && r < 0x7f:
+			width++
+		case unicode.IsControl(r) || unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		case isWide(r):
+			width += 2
+		default:
+			width++
+		}
+	}
+	return width
+}
+
+//-----------------------------------------
+// Private Utility Types & Functions
+//-----------------------------------------
+
+// wideRanges are the ranges of East Asian wide and fullwidth characters, and of emoji.
+var wideRanges = [][2]rune{
+	{0x1100, 0x115f}, {0x2e80, 0x303e}, {0x3041, 0x33ff}, {0x3400, 0x4dbf},
+	{0x4e00, 0x9fff}, {0xa000, 0xa4cf}, {0xac00, 0xd7a3}, {0xf900, 0xfaff},
+	{0xfe30, 0xfe4f}, {0xff00, 0xff60}, {0xffe0, 0xffe6}, {0x1f300, 0x1f64f},
+	{0x1f900, 0x1f9ff}, {0x20000, 0x3fffd},
+}
+
+// isWide returns true if r occupies two terminal cells.
+func isWide(r rune) bool {
+	for _, wide := range wideRanges {
+		if r < wide[0] {
+			return false
+		}
+		if r <= wide[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// padding returns n spaces, or none if n is not positive.
+func padding(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.Repeat(" ", n)
+}
+
+// columnLayout finds the most columns into which cells of the given widths, laid out down
+// the columns, fit within width. It returns the number of rows, and the width of each column.
+// A single column is used if nothing else fits.
+func columnLayout(widths []int, width int) (int, []int) {
+	// no more columns than the narrowest cells could fill
+	narrowest := width
+	for _, w := range widths {
+		if w < narrowest {
+			narrowest = w
+		}
+	}
+	most := len(widths)
+	if fit := (width + COLUMN_SPACING) / (narrowest + COLUMN_SPACING); fit < most {
+		most = fit
+	}
+	for cols := most; cols > 1; cols-- {
+		rows := (len(widths) + cols - 1) / cols
+		// skip counts which leave the last column empty; fewer columns give the same rows
+		if (cols-1)*rows >= len(widths) {
+			continue
+		}
+		columnWidths := make([]int, cols)
+		total := (cols - 1) * COLUMN_SPACING
+		for i, w := range widths {
+			if col := i / rows; w > columnWidths[col] {
+				total += w - columnWidths[col]
+				columnWidths[col] = w
+			}
+		}
+		if total <= width {
+			return rows, columnWidths
+		}
+	}
+	max := 0
+	for _, w := range widths {
+		if w > max {
+			max = w
+		}
+	}
+	return len(widths), []int{max}
+}
@@ -0,0 +1,153 @@
+package lss
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormat_DisplayWidth(t *testing.T) {
+	tests := map[string]int{
+		"":             0,
+		"foo.%04d.exr": 12,
+		"日本語.txt":      10,
+		"café.exr":     8,
+		"café":        4,
+		"tab\there":    7,
+	}
+	for s, expected := range tests {
+		if width := DisplayWidth(s); width != expected {
+			t.Error("DisplayWidth(", s, ") =", width, "Should Be:", expected)
+		}
+	}
+}
+
+func TestFormat_LinesAlign(t *testing.T) {
+	seqs := SequencesFromStringSlice([]string{
+		"日本.0001.exr", "日本.0002.exr", "foo.1.exr", "foo.2.exr", "foo.4.exr", "notes.txt",
+	})
+	f := &Formatter{Colors: DefaultColors()}
+	lines := f.Lines(seqs)
+	plain := (&Formatter{}).Lines(seqs)
+	column := -1
+	for i, line := range lines {
+		if stripped := stripColors(line); stripped != plain[i] {
+			t.Error("colored line", stripped, "differs from plain line", plain[i])
+		}
+		if seqs[i].Len() == 1 {
+			continue
+		}
+		// the frames column starts at the same cell on every line
+		frames := plain[i][strings.LastIndexByte(plain[i], ' ')+1:]
+		at := DisplayWidth(plain[i]) - DisplayWidth(frames)
+		if column >= 0 && at != column {
+			t.Error("misaligned lines:", plain)
+		}
+		column = at
+	}
+	if !strings.Contains(lines[0], "\033[01;33mfoo.%d.exr\033[0m") {
+		t.Error("sequence with gaps was not painted:", lines[0])
+	}
+}
+
+func TestFormat_Kind(t *testing.T) {
+	f := &Formatter{IsDir: func(name string) bool { return name == "render" }}
+	tests := map[string]Kind{
+		"foo.%04d.exr 1-3":   KIND_SEQUENCE,
+		"foo.%04d.exr 1-2,4": KIND_GAPS,
+		"notes.txt":          KIND_SINGLE,
+		"render":             KIND_DIRECTORY,
+	}
+	names := map[string][]string{
+		"foo.%04d.exr 1-3":   {"foo.0001.exr", "foo.0002.exr", "foo.0003.exr"},
+		"foo.%04d.exr 1-2,4": {"foo.0001.exr", "foo.0002.exr", "foo.0004.exr"},
+		"notes.txt":          {"notes.txt"},
+		"render":             {"render"},
+	}
+	for desc, expected := range tests {
+		seq := SequencesFromStringSlice(names[desc])[0]
+		if kind := f.Kind(&seq); kind != expected {
+			t.Error(desc, "is of kind", kind, "Should Be:", expected)
+		}
+	}
+}
+
+func TestFormat_ParseColors(t *testing.T) {
+	err, colors := ParseColors("sq=0:di=04;31:zz=1:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if colors[KIND_DIRECTORY] != "04;31" || colors[KIND_GAPS] != DefaultColors()[KIND_GAPS] {
+		t.Error("ParseColors returned", colors)
+	}
+	if painted := colors.Paint(KIND_SEQUENCE, "foo"); painted != "foo" {
+		t.Error("sq=0 should not paint, but painted", painted)
+	}
+	for _, spec := range []string{"di", "di=bold", "di=1;\033"} {
+		if err, _ := ParseColors(spec); err == nil {
+			t.Error("ParseColors(", spec, ") should fail")
+		}
+	}
+}
+
+func TestFormat_Columns(t *testing.T) {
+	names := []string{}
+	for _, name := range strings.Fields("a bb ccc dddd eeeee ffffff g h i j") {
+		names = append(names, name+".txt")
+	}
+	seqs := SequencesFromStringSlice(names)
+	for _, width := range []int{1, 20, 40, 80, 200} {
+		lines := (&Formatter{}).Columns(seqs, width)
+		seen := 0
+		for _, line := range lines {
+			if DisplayWidth(line) > width && len(lines) < len(seqs) {
+				t.Error("line", line, "is wider than", width)
+			}
+			seen += len(strings.Fields(line))
+		}
+		if seen != len(seqs) {
+			t.Error("columns at", width, "were", lines)
+		}
+	}
+	if lines := (&Formatter{}).Columns(seqs, 200); len(lines) != 1 {
+		t.Error("wide terminal should list on one line, but listed", lines)
+	}
+	lines := (&Formatter{}).Columns(seqs, 30)
+	if lines[0] != "a.txt     eeeee.txt   i.txt" || lines[3] != "dddd.txt  h.txt" {
+		t.Error("columns were", lines)
+	}
+
+	// the layout of a huge directory is found without trying every column count
+	widths := make([]int, 200000)
+	for i := range widths {
+		widths[i] = 10
+	}
+	if rows, columnWidths := columnLayout(widths, 80); rows != 33334 || len(columnWidths) != 6 {
+		t.Error("columnLayout of", len(widths), "cells gave", rows, "rows of", len(columnWidths), "columns")
+	}
+}
+
+// stripColors removes SGR escape sequences from s.
+func stripColors(s string) string {
+	for {
+		start := strings.Index(s, "\033[")
+		if start < 0 {
+			return s
+		}
+		end := strings.IndexByte(s[start:], 'm')
+		s = s[:start] + s[start+end+1:]
+	}
+}
+
+func TestFormat_LinesTimecode(t *testing.T) {
+	seqs := SequencesFromStringSlice([]string{"foo.1001.exr", "foo.1002.exr", "foo.1048.exr", "bar.1.exr", "bar.2.exr", "notes.txt"})
+	_, rate := ParseRate("24")
+	lines := (&Formatter{Rate: rate}).Lines(seqs)
+	expected := []string{
+		"2     bar.%d.exr      1-2               00:00:00:01  00:00:00:02  00:00:00:02",
+		"3     foo.%04d.exr    1001-1002,1048    00:00:41:17  00:00:43:16  00:00:02:00",
+		"1     notes.txt",
+	}
+	if !testEq(lines, expected) {
+		t.Errorf("Lines with a rate returned\n%s\nShould Be:\n%s", strings.Join(lines, "\n"), strings.Join(expected, "\n"))
+	}
+}
@@ -0,0 +1,96 @@
+package lss
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MAX_RANGE_FRAMES is the most frames ParseFrameRange will expand a range to, so that a slip
+// of the keyboard (eg 0-2000000000) is reported rather than exhausting memory.
+const MAX_RANGE_FRAMES = 1000000
+
+// FrameRangeString - given an ascending slice of frame numbers, return the condensed
+// range form used throughout lss.
+// egs
+// FrameRangeString([]int{1,2,3,5}) returns "1-3,5"
+// FrameRangeString([]int{}) returns ""
+func FrameRangeString(frames []int) string {
+	return formatRanges(frames, "-", ",")
+}
+
+// formatRanges writes ascending frames in condensed range form, separating the first and
+// last frames of each run with rangeSep, and the runs with listSep.
+func formatRanges(frames []int, rangeSep string, listSep string) string {
+	// build into a byte slice; listings with many gaps would otherwise be quadratic
+	ret := []byte{}
+	for i := 0; i < len(frames); {
+		// walk to the end of the contiguous run starting at i
+		j := i
+		for j+1 < len(frames) && frames[j+1] == frames[j]+1 {
+			j++
+		}
+		if len(ret) > 0 {
+			ret = append(ret, listSep...)
+		}
+		ret = strconv.AppendInt(ret, int64(frames[i]), 10)
+		if j > i {
+			ret = append(ret, rangeSep...)
+			ret = strconv.AppendInt(ret, int64(frames[j]), 10)
+		}
+		i = j + 1
+	}
+	return string(ret)
+}
+
+// ParseFrameRange parses frames written in condensed range form, as FrameRangeString writes
+// them (eg 1-3,5), into ascending frame numbers without duplicates. A range may also carry a
+// step (eg 1-100x2 for the odd frames from 1 to 99). Ranges totalling more than
+// MAX_RANGE_FRAMES frames are refused.
+func ParseFrameRange(s string) (error, []int) {
+	seen := map[int]bool{}
+	frames := []int{}
+	total := 0
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		bad := errors.New("bad frame range '" + part + "' (expected eg 7, 1-10 or 1-100x2)")
+		span, stepText, hasStep := strings.Cut(part, "x")
+		firstText, lastText, isRange := strings.Cut(span, "-")
+		if !isRange {
+			lastText = firstText
+		}
+		first, err := strconv.Atoi(firstText)
+		if err != nil || first < 0 {
+			return bad, nil
+		}
+		last, err := strconv.Atoi(lastText)
+		if err != nil || last < first {
+			return bad, nil
+		}
+		step := 1
+		if hasStep {
+			if step, err = strconv.Atoi(stepText); err != nil || step < 1 || !isRange {
+				return bad, nil
+			}
+		}
+		count := (last-first)/step + 1
+		if total += count; count < 0 || total > MAX_RANGE_FRAMES {
+			return tooManyFrames(s), nil
+		}
+		for i := 0; i < count; i++ {
+			frame := first + i*step
+			if !seen[frame] {
+				seen[frame] = true
+				frames = append(frames, frame)
+			}
+		}
+	}
+	sort.Ints(frames)
+	return nil, frames
+}
+
+// tooManyFrames returns the error for a range of more than MAX_RANGE_FRAMES frames.
+func tooManyFrames(s string) error {
+	return errors.New("frame range '" + s + "' holds more than " + strconv.Itoa(MAX_RANGE_FRAMES) + " frames")
+}
@@ -0,0 +1,50 @@
+package lss
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFrames_FrameRangeString(t *testing.T) {
+	tests := map[string][]int{
+		"":            []int{},
+		"7":           []int{7},
+		"1-3":         []int{1, 2, 3},
+		"1-3,5":       []int{1, 2, 3, 5},
+		"0-1,3":       []int{0, 1, 3},
+		"1,3-4,6":     []int{1, 3, 4, 6},
+		"5-6,8-9,100": []int{5, 6, 8, 9, 100},
+	}
+	for expected, frames := range tests {
+		if result := FrameRangeString(frames); result != expected {
+			t.Error("FrameRangeString", frames, "returned", result, "Should Be:", expected)
+		}
+	}
+}
+
+func TestFrames_ParseFrameRange(t *testing.T) {
+	tests := map[string][]int{
+		"7":        {7},
+		"1-3":      {1, 2, 3},
+		"1-3,5":    {1, 2, 3, 5},
+		"5,1-3,2":  {1, 2, 3, 5},
+		"1-10x3":   {1, 4, 7, 10},
+		"0-1, 100": {0, 1, 100},
+	}
+	for text, expected := range tests {
+		err, frames := ParseFrameRange(text)
+		if err != nil || !reflect.DeepEqual(frames, expected) {
+			t.Error("ParseFrameRange", text, "returned", frames, err, "Should Be:", expected)
+		}
+	}
+	for _, bad := range []string{"", "a", "3-1", "-2", "1-", "1-10x0", "5x2", "1,,2", "0-2000000000", "0-600000,0-600000"} {
+		if err, _ := ParseFrameRange(bad); err == nil {
+			t.Error("ParseFrameRange accepted", bad)
+		}
+	}
+	for _, frames := range [][]int{{1}, {1, 2, 3, 5}, {5, 6, 8, 9, 100}} {
+		if err, parsed := ParseFrameRange(FrameRangeString(frames)); err != nil || !reflect.DeepEqual(parsed, frames) {
+			t.Error("ParseFrameRange did not round trip", frames, "returning", parsed, err)
+		}
+	}
+}
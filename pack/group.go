@@ -0,0 +1,396 @@
+package lss
+
+/*
+group provides the grouping engine which collapses DirItems into Sequences. Grouping is
+synchronous and deterministic: each Sequence is built once, and the result does not depend
+upon the order in which the items are supplied.
+
+Items are first gathered into families sharing a prefix and extension. Within a family, each
+item's padding is classified by DirItem.Padded:
+
+PADDED_NO     - a single digit (eg foo.7.exr), which can only be unpadded
+PADDED_YES    - a leading zero (eg foo.0007.exr), which can only be padded
+PADDED_EITHER - no leading zero and more than one digit (eg foo.100.exr), which may belong to
+                either a padded sequence of the same width, or an unpadded sequence
+
+PADDED_EITHER items are resolved using the rest of the family:
+
+1. if the family holds PADDED_YES items of the same width, the item is padded
+   (foo.0999.exr, foo.1000.exr => foo.%04d.exr 999-1000)
+2. otherwise, if the family holds PADDED_NO items, or PADDED_EITHER items of another width,
+   the item is unpadded
+   (foo.9.exr, foo.10.exr => foo.%d.exr 9-10)
+3. otherwise the item is padded
+   (foo.1001.exr, foo.1002.exr => foo.%04d.exr 1001-1002)
+
+A PaddingPolicy other than POLICY_AUTO overrides these rules for every PADDED_EITHER item, and
+each resolution is recorded as a PaddingDecision, so that it can be explained to the user.
+*/
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+)
+
+//-------------------------
+// Type PaddingPolicy
+//-------------------------
+
+// PaddingPolicy determines how PADDED_EITHER items are placed.
+type PaddingPolicy int
+
+const (
+	POLICY_AUTO            PaddingPolicy = iota // resolve by the rules above
+	POLICY_PREFER_PADDED                        // always join the padded sequence
+	POLICY_PREFER_UNPADDED                      // always join the unpadded sequence
+	POLICY_SPLIT                                // keep them in an unpadded sequence of their own
+)
+
+var policyNames = []string{"auto", "prefer-padded", "prefer-unpadded", "split"}
+
+// ParsePaddingPolicy returns the PaddingPolicy named by name, one of auto, prefer-padded,
+// prefer-unpadded or split.
+func ParsePaddingPolicy(name string) (error, PaddingPolicy) {
+	for i, policyName := range policyNames {
+		if name == policyName {
+			return nil, PaddingPolicy(i)
+		}
+	}
+	return errors.New("unknown padding policy:'" + name + "' (expected auto, prefer-padded, prefer-unpadded or split)"), POLICY_AUTO
+}
+
+// String returns the name of the policy, as accepted by ParsePaddingPolicy.
+func (p PaddingPolicy) String() string {
+	if p < 0 || int(p) >= len(policyNames) {
+		return "unknown"
+	}
+	return policyNames[p]
+}
+
+//-------------------------
+// Type PaddingDecision
+//-------------------------
+
+// PaddingDecision records the placement of the PADDED_EITHER items of one width within a
+// family.
+type PaddingDecision struct {
+	Items      Sequence // the ambiguous items
+	Candidates []string // patterns of the sequences the items could join, unpadded first
+	Chosen     string   // pattern of the sequence joined, or "" if the items were kept apart
+	Apart      string   // pattern of the sequence of their own the items were kept apart in
+	Rule       int      // the number of the rule which decided (see above), or 0 for a policy
+	Reason     string   // why the rule or policy chose as it did
+}
+
+//-------------------------
+// Type SequenceBuilder
+//-------------------------
+
+// SequenceBuilder collapses names into Sequences incrementally, so that a directory may be
+// fed to it in batches as it is read. Only the frame numbers of each candidate sequence are
+// retained, rather than the names themselves, which keeps memory use proportional to the
+// number of entries rather than to their length.
+type SequenceBuilder struct {
+	families map[familyKey]*family
+	singles  []string
+	debug    bool // log the classification of each entry
+
+	policy    PaddingPolicy
+	decisions []PaddingDecision
+}
+
+// NewSequenceBuilder returns an empty SequenceBuilder.
+func NewSequenceBuilder() *SequenceBuilder {
+	return &SequenceBuilder{families: map[familyKey]*family{}, debug: debugEnabled()}
+}
+
+// Add adds a directory entry name to the builder. Names which the DirItem parser cannot
+// reproduce exactly (eg foo.12abc) are kept as single files.
+func (b *SequenceBuilder) Add(name string) {
+	item, exact := ScanName(name)
+	if !exact {
+		if b.debug {
+			logger.Debug("single file", "name", name, "reason", singleReason(name, &item))
+		}
+		b.singles = append(b.singles, name)
+		return
+	}
+	b.add(item.Prefix, item.Number, item.Padding, item.Extension)
+}
+
+// AddItem adds a DirItem to the builder. Items without a number are kept as single files.
+func (b *SequenceBuilder) AddItem(item *DirItem) {
+	if item.Number < 0 {
+		if b.debug {
+			logger.Debug("single file", "name", item.Prefix, "reason", "no frame number")
+		}
+		b.singles = append(b.singles, item.Prefix)
+		return
+	}
+	b.add(item.Prefix, item.Number, item.Padding, item.Extension)
+}
+
+// Len returns the number of names added so far.
+func (b *SequenceBuilder) Len() int {
+	cnt := len(b.singles)
+	for _, fam := range b.families {
+		cnt += len(fam.unpadded)
+		for _, frames := range fam.padded {
+			cnt += len(frames)
+		}
+		for _, frames := range fam.either {
+			cnt += len(frames)
+		}
+	}
+	return cnt
+}
+
+// SetPolicy sets the PaddingPolicy used to place PADDED_EITHER items. The default is
+// POLICY_AUTO.
+func (b *SequenceBuilder) SetPolicy(policy PaddingPolicy) {
+	b.policy = policy
+}
+
+// Sequences resolves the padding of each family (see above) and returns the resulting
+// Sequences, ordered naturally by prefix, then by extension, padding and first frame. The
+// decisions made for ambiguous items are available from Decisions afterwards.
+func (b *SequenceBuilder) Sequences() []Sequence {
+	seqs := make([]Sequence, 0, len(b.singles)+len(b.families))
+	for _, name := range b.singles {
+		seqs = append(seqs, Sequence{Prefix: name, Padding: -1})
+	}
+	b.decisions = []PaddingDecision{}
+
+	for key, fam := range b.families {
+		unpadded := fam.unpadded
+		padded := map[int][]int{}
+		for width, frames := range fam.padded {
+			padded[width] = frames
+		}
+		split, splitDecisions := map[int][]int{}, map[int]int{}
+		for width, frames := range fam.either {
+			as, rule, reason := b.resolve(fam, width)
+			switch as {
+			case PADDED_YES:
+				padded[width] = append(padded[width], frames...)
+			case PADDED_NO:
+				unpadded = append(unpadded, frames...)
+			default:
+				split[width] = frames
+				splitDecisions[width] = len(b.decisions)
+			}
+
+			unpaddedSeq, paddedSeq := newSequence(key, 1, nil), newSequence(key, width, nil)
+			decision := PaddingDecision{
+				Items:      newSequence(key, width, append([]int{}, frames...)),
+				Candidates: []string{unpaddedSeq.Pattern(), paddedSeq.Pattern()},
+				Rule:       rule,
+				Reason:     reason,
+			}
+			switch as {
+			case PADDED_NO:
+				decision.Chosen = decision.Candidates[0]
+			case PADDED_YES:
+				decision.Chosen = decision.Candidates[1]
+			}
+			b.decisions = append(b.decisions, decision)
+			if b.debug {
+				logger.Debug("resolved either", "prefix", key.prefix, "extension", key.extension,
+					"width", width, "frames", len(frames), "as", as.String(), "reason", reason)
+			}
+		}
+
+		apart := b.placeSplit(key, split, splitDecisions, len(unpadded) > 0, padded)
+		first := len(seqs)
+		if len(unpadded) > 0 {
+			seqs = append(seqs, newSequence(key, 1, unpadded))
+		}
+		for width, frames := range padded {
+			seqs = append(seqs, newSequence(key, width, frames))
+		}
+		seqs = append(seqs, apart...)
+		if b.debug && len(seqs)-first > 1 {
+			patterns := []string{}
+			for i := first; i < len(seqs); i++ {
+				patterns = append(patterns, seqs[i].Pattern())
+			}
+			logger.Debug("family split", "prefix", key.prefix, "extension", key.extension,
+				"sequences", patterns, "reason", "frames differ in padding")
+		}
+	}
+
+	sort.Slice(seqs, func(i, j int) bool {
+		return sequenceLess(&seqs[i], &seqs[j])
+	})
+	sort.Slice(b.decisions, func(i, j int) bool {
+		return sequenceLess(&b.decisions[i].Items, &b.decisions[j].Items)
+	})
+	logger.Info("grouped", "families", len(b.families), "singles", len(b.singles),
+		"sequences", len(seqs)-len(b.singles))
+	return seqs
+}
+
+// placeSplit places the PADDED_EITHER items which the policy split from the rest of their
+// family, by width, into sequences of their own: unpadded where the family has no unpadded
+// sequence, or else padded to their width, so that no two sequences share a pattern. Items
+// for which both patterns are taken join the padded sequence of their width, which is added
+// to padded. The decisions recorded for the items, at the indices in decisions, are updated.
+func (b *SequenceBuilder) placeSplit(key familyKey, split map[int][]int, decisions map[int]int,
+	hasUnpadded bool, padded map[int][]int) []Sequence {
+	widths := make([]int, 0, len(split))
+	for width := range split {
+		widths = append(widths, width)
+	}
+	sort.Ints(widths)
+
+	apart := []Sequence{}
+	for _, width := range widths {
+		frames, decision := split[width], &b.decisions[decisions[width]]
+		switch {
+		case !hasUnpadded:
+			hasUnpadded = true
+			apart = append(apart, newSequence(key, 1, frames))
+			decision.Apart = decision.Candidates[0]
+		case padded[width] == nil:
+			padded[width] = frames
+			decision.Apart = decision.Candidates[1]
+		default:
+			padded[width] = append(padded[width], frames...)
+			decision.Chosen = decision.Candidates[1]
+			decision.Reason += ", but " + decision.Candidates[0] + " and " + decision.Candidates[1] + " were both taken"
+		}
+	}
+	return apart
+}
+
+// Decisions returns the placement of each group of PADDED_EITHER items made by the last
+// call to Sequences, ordered as the Sequences are.
+func (b *SequenceBuilder) Decisions() []PaddingDecision {
+	return b.decisions
+}
+
+//-------------------------
+// Grouping Functions
+//-------------------------
+
+// GroupDirItems collapses items into Sequences, ordered naturally by prefix, then by
+// extension, padding and first frame. Items without a number become single file Sequences.
+func GroupDirItems(items DirItemList) []Sequence {
+	builder := NewSequenceBuilder()
+	for i := range items {
+		builder.AddItem(&items[i])
+	}
+	return builder.Sequences()
+}
+
+// RegroupSequences collapses the members of seqs which the filter keeps (all of them, if the
+// filter is nil) again, placing ambiguous items according to policy. It returns the new
+// Sequences along with the decisions made.
+func RegroupSequences(seqs []Sequence, filter func(string) bool, policy PaddingPolicy) ([]Sequence, []PaddingDecision) {
+	debug := debugEnabled()
+	builder := NewSequenceBuilder()
+	builder.SetPolicy(policy)
+	for i := range seqs {
+		for _, name := range seqs[i].Names() {
+			if filter == nil || filter(name) {
+				builder.Add(name)
+			} else if debug {
+				logger.Debug("filtered out", "name", name)
+			}
+		}
+	}
+	return builder.Sequences(), builder.Decisions()
+}
+
+//-----------------------------------------
+// Private Utility Types & Functions
+//-----------------------------------------
+
+// familyKey identifies the items which may end up in the same sequence.
+type familyKey struct {
+	prefix    string
+	extension string
+}
+
+// family records the frames seen within a family, by padding.
+type family struct {
+	unpadded []int         // frames of PADDED_NO items
+	padded   map[int][]int // frames of PADDED_YES items, by width
+	either   map[int][]int // frames of PADDED_EITHER items, by width
+}
+
+// add files a numbered item under its family.
+func (b *SequenceBuilder) add(prefix string, number int, padding int, extension string) {
+	key := familyKey{prefix, extension}
+	fam, ok := b.families[key]
+	if !ok {
+		if b.debug {
+			logger.Debug("new family", "prefix", prefix, "extension", extension,
+				"reason", "no earlier entry shares the prefix and extension")
+		}
+		fam = &family{padded: map[int][]int{}, either: map[int][]int{}}
+		b.families[key] = fam
+	}
+	di := DirItem{prefix, number, padding, extension}
+	class := di.Padded()
+	switch class {
+	case PADDED_NO:
+		fam.unpadded = append(fam.unpadded, number)
+	case PADDED_YES:
+		fam.padded[padding] = append(fam.padded[padding], number)
+	default:
+		fam.either[padding] = append(fam.either[padding], number)
+	}
+	if b.debug {
+		reason := "single digit frame number"
+		switch class {
+		case PADDED_YES:
+			reason = "frame number has a leading zero"
+		case PADDED_EITHER:
+			reason = "frame number has " + strconv.Itoa(padding) + " digits and no leading zero"
+		}
+		logger.Debug("classified", "name", di.String(), "padding", class.String(), "reason", reason)
+	}
+}
+
+// resolve decides whether the PADDED_EITHER items of the given width within fam are padded
+// (PADDED_YES), unpadded (PADDED_NO) or split out on their own (PADDED_EITHER), returning
+// the number of the rule which decided (0 for a policy) and the reason.
+func (b *SequenceBuilder) resolve(fam *family, width int) (Padding, int, string) {
+	switch b.policy {
+	case POLICY_PREFER_PADDED:
+		return PADDED_YES, 0, "padding policy prefer-padded"
+	case POLICY_PREFER_UNPADDED:
+		return PADDED_NO, 0, "padding policy prefer-unpadded"
+	case POLICY_SPLIT:
+		return PADDED_EITHER, 0, "padding policy split"
+	}
+	switch {
+	case fam.padded[width] != nil:
+		return PADDED_YES, 1, "family holds zero padded frames of the same width"
+	case len(fam.unpadded) > 0:
+		return PADDED_NO, 2, "family holds single digit frames"
+	case len(fam.either) > 1:
+		return PADDED_NO, 2, "family holds frames of other widths without leading zeros"
+	}
+	return PADDED_YES, 3, "all of the family's frames share the width"
+}
+
+// singleReason explains why a name, scanned as item, is not a sequence member.
+func singleReason(name string, item *DirItem) string {
+	switch {
+	case item.Number >= 0:
+		return "name does not end with the frame number and extension"
+	case item.Prefix != name:
+		return "frame number is too large"
+	default:
+		return "no frame number"
+	}
+}
+
+// newSequence builds a Sequence of the family from unsorted frames.
+func newSequence(key familyKey, padding int, frames []int) Sequence {
+	sort.Ints(frames)
+	return Sequence{Prefix: key.prefix, Padding: padding, Extension: key.extension, Frames: frames}
+}
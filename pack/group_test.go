@@ -0,0 +1,341 @@
+package lss
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/xlab/handysort"
+)
+
+// randomNames generates a directory's worth of names mixing padded, unpadded and ambiguous
+// frame numbers across a handful of prefixes and extensions.
+func randomNames(rng *rand.Rand) []string {
+	prefixes := []string{"foo", "foo_v2", "bar", "shot.010"}
+	exts := []string{"", ".exr", ".dpx"}
+	seen := map[string]bool{}
+	names := []string{}
+	for n := rng.Intn(60); n >= 0; n-- {
+		var name string
+		if rng.Intn(10) == 0 {
+			name = "single" + strconv.Itoa(rng.Intn(5)) + ".txt"
+		} else {
+			number := []int{rng.Intn(12), rng.Intn(120), 990 + rng.Intn(20)}[rng.Intn(3)]
+			digits := strconv.Itoa(number)
+			if width := rng.Intn(6); width > len(digits) {
+				digits = strings.Repeat("0", width-len(digits)) + digits
+			}
+			name = prefixes[rng.Intn(len(prefixes))] + "." + digits + exts[rng.Intn(len(exts))]
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// referenceGroup is a brute force implementation of the grouping rules, which examines the
+// raw digits of every other item in the family for each item it places.
+func referenceGroup(names []string) []Sequence {
+	items := NewDirItemListFromSlice(names)
+	type key struct {
+		prefix, ext string
+		padding     int
+	}
+	frames := map[key][]int{}
+	singles := []Sequence{}
+
+	for _, item := range items {
+		if item.Number < 0 {
+			singles = append(singles, Sequence{Prefix: item.Prefix, Padding: -1})
+			continue
+		}
+		digits := item.GetPaddedNumber()
+		width := len(digits)
+		padding := width
+		switch {
+		case width == 1:
+			padding = 1
+		case digits[0] == '0':
+			padding = width
+		default:
+			zeroed, unpadded := false, false
+			for _, other := range items {
+				if other.Number < 0 || other.Prefix != item.Prefix || other.Extension != item.Extension {
+					continue
+				}
+				od := other.GetPaddedNumber()
+				if od[0] == '0' && len(od) == width && len(od) > 1 {
+					zeroed = true
+				}
+				if len(od) == 1 || (od[0] != '0' && len(od) != width) {
+					unpadded = true
+				}
+			}
+			if !zeroed && unpadded {
+				padding = 1
+			}
+		}
+		k := key{item.Prefix, item.Extension, padding}
+		frames[k] = append(frames[k], item.Number)
+	}
+
+	seqs := singles
+	for k, f := range frames {
+		sort.Ints(f)
+		seqs = append(seqs, Sequence{Prefix: k.prefix, Padding: k.padding, Extension: k.ext, Frames: f})
+	}
+	sort.Slice(seqs, func(i, j int) bool {
+		a, b := seqs[i], seqs[j]
+		if a.Prefix != b.Prefix {
+			return handysort.StringLess(a.Prefix, b.Prefix)
+		}
+		if a.Extension != b.Extension {
+			return a.Extension < b.Extension
+		}
+		if a.Padding != b.Padding {
+			return a.Padding < b.Padding
+		}
+		return a.First() < b.First()
+	})
+	return seqs
+}
+
+func TestGroup_MatchesReference(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		names := randomNames(rng)
+		got := SequencesFromStringSlice(names)
+		want := referenceGroup(names)
+		if !reflect.DeepEqual(got, want) {
+			t.Fatal("grouping of", names, "\nwas:      ", got, "\nShould Be:", want)
+		}
+	}
+}
+
+func TestGroup_OrderIndependent(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for i := 0; i < 500; i++ {
+		names := randomNames(rng)
+		expected := SequencesFromStringSlice(names)
+		rng.Shuffle(len(names), func(i, j int) { names[i], names[j] = names[j], names[i] })
+		if got := SequencesFromStringSlice(names); !reflect.DeepEqual(got, expected) {
+			t.Fatal("shuffling", names, "changed the grouping from", expected, "to", got)
+		}
+	}
+}
+
+func TestGroup_Partition(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	for i := 0; i < 500; i++ {
+		names := randomNames(rng)
+		seqs := SequencesFromStringSlice(names)
+
+		recovered := []string{}
+		for j, seq := range seqs {
+			if j > 0 && sequenceLess(&seqs[j], &seqs[j-1]) {
+				t.Fatal("sequences out of order:", seqs[j-1], seqs[j])
+			}
+			for k := 1; k < len(seq.Frames); k++ {
+				if seq.Frames[k] <= seq.Frames[k-1] {
+					t.Fatal("frames not strictly ascending:", seq)
+				}
+			}
+			recovered = append(recovered, seq.Names()...)
+		}
+		sort.Strings(recovered)
+		sorted := append([]string{}, names...)
+		sort.Strings(sorted)
+		if !testEq(recovered, sorted) {
+			t.Fatal("names", sorted, "were grouped into", recovered)
+		}
+	}
+}
+
+func TestGroup_Rules(t *testing.T) {
+	tests := map[string][]string{
+		"foo.%04d.exr 999-1000":         {"foo.0999.exr", "foo.1000.exr"},
+		"foo.%d.exr 9-10":               {"foo.9.exr", "foo.10.exr"},
+		"foo.%04d.exr 1001-1002":        {"foo.1001.exr", "foo.1002.exr"},
+		"foo.%d.exr 99-100":             {"foo.99.exr", "foo.100.exr"},
+		"foo.%d.exr 1,10-11,100":        {"foo.10.exr", "foo.100.exr", "foo.11.exr", "foo.1.exr"},
+		"foo.%d.exr 2|foo.%04d.exr 1-2": {"foo.0001.exr", "foo.0002.exr", "foo.2.exr"},
+	}
+	for expected, names := range tests {
+		result := []string{}
+		for _, seq := range SequencesFromStringSlice(names) {
+			result = append(result, seq.String())
+		}
+		if strings.Join(result, "|") != expected {
+			t.Error(names, "grouped as", result, "Should Be:", expected)
+		}
+	}
+}
+
+func TestGroup_UnpaddedOnlyListing(t *testing.T) {
+	// an unpadded only listing used to index past the end of the merge buffer
+	lines := []string{}
+	for line := range RangesChanFromStringSlice([]string{"x.1.exr", "x.2.exr", "x.3.exr"}) {
+		lines = append(lines, line)
+	}
+	if len(lines) != 1 || strings.Join(strings.Fields(lines[0]), " ") != "3 x.%d.exr 1-3" {
+		t.Error("unpadded listing was", lines)
+	}
+}
+
+func TestGroup_NaturalOrder(t *testing.T) {
+	lines := []string{}
+	for line := range RangesChanFromStringSlice([]string{
+		"shot10.0001.exr", "shot2.1.exr", "shot2.2.exr", "shot10.0002.exr", "Shot3.txt",
+	}) {
+		lines = append(lines, strings.Fields(line)[1])
+	}
+	expected := []string{"Shot3.txt", "shot2.%d.exr", "shot10.%04d.exr"}
+	if !testEq(lines, expected) {
+		t.Error("listing order was", lines, "Should Be:", expected)
+	}
+}
+
+func TestGroup_BuilderBatches(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	for i := 0; i < 100; i++ {
+		names := randomNames(rng)
+		builder := NewSequenceBuilder()
+		for _, name := range names {
+			builder.Add(name)
+		}
+		if builder.Len() != len(names) {
+			t.Fatal("builder holds", builder.Len(), "names. Should Be:", len(names))
+		}
+		if got, want := builder.Sequences(), GroupDirItems(NewDirItemListFromSlice(names)); !reflect.DeepEqual(got, want) {
+			t.Fatal("building", names, "\nwas:      ", got, "\nShould Be:", want)
+		}
+	}
+}
+
+func TestGroup_PaddingPolicy(t *testing.T) {
+	names := []string{"foo.099.exr", "foo.99.exr", "foo.100.exr", "foo.7.exr"}
+	tests := map[string]string{
+		"auto":            "foo.%d.exr 7,99|foo.%03d.exr 99-100",
+		"prefer-padded":   "foo.%d.exr 7|foo.%02d.exr 99|foo.%03d.exr 99-100",
+		"prefer-unpadded": "foo.%d.exr 7,99-100|foo.%03d.exr 99",
+		"split":           "foo.%d.exr 7|foo.%02d.exr 99|foo.%03d.exr 99-100",
+	}
+	for name, expected := range tests {
+		err, policy := ParsePaddingPolicy(name)
+		if err != nil || policy.String() != name {
+			t.Fatal("ParsePaddingPolicy(", name, ") returned", policy, err)
+		}
+		builder := NewSequenceBuilder()
+		builder.SetPolicy(policy)
+		for _, n := range names {
+			builder.Add(n)
+		}
+		result := []string{}
+		for _, seq := range builder.Sequences() {
+			result = append(result, seq.String())
+		}
+		if strings.Join(result, "|") != expected {
+			t.Error(name, "grouped as", result, "Should Be:", expected)
+		}
+	}
+	builder := NewSequenceBuilder()
+	builder.SetPolicy(POLICY_SPLIT)
+	for _, n := range []string{"foo.0998.exr", "foo.0999.exr", "foo.1000.exr", "foo.1001.exr"} {
+		builder.Add(n)
+	}
+	if seqs := builder.Sequences(); len(seqs) != 2 || seqs[0].Pattern() == seqs[1].Pattern() {
+		t.Error("split grouped as", seqs, "Should Be: foo.%d.exr 1000-1001|foo.%04d.exr 998-999")
+	}
+	// split never writes two sequences with the same pattern
+	rng := rand.New(rand.NewSource(5))
+	for i := 0; i < 100; i++ {
+		builder := NewSequenceBuilder()
+		builder.SetPolicy(POLICY_SPLIT)
+		names := randomNames(rng)
+		for _, n := range names {
+			builder.Add(n)
+		}
+		patterns := map[string]bool{}
+		for _, seq := range builder.Sequences() {
+			if pattern := seq.Pattern(); !seq.Single() && patterns[pattern] {
+				t.Fatal("split of", names, "wrote", pattern, "twice")
+			}
+			patterns[seq.Pattern()] = true
+		}
+	}
+	if err, _ := ParsePaddingPolicy("padded"); err == nil {
+		t.Error("ParsePaddingPolicy should reject unknown policies")
+	}
+}
+
+func TestGroup_Decisions(t *testing.T) {
+	builder := NewSequenceBuilder()
+	for _, name := range []string{"foo.0999.exr", "foo.1000.exr", "bar.9.exr", "bar.10.exr", "bar.11.exr", "baz.1001.exr", "qux.0001.exr"} {
+		builder.Add(name)
+	}
+	builder.Sequences()
+	expected := []string{
+		"bar.%02d.exr 10-11|bar.%d.exr bar.%02d.exr|bar.%d.exr|2",
+		"baz.%04d.exr 1001|baz.%d.exr baz.%04d.exr|baz.%04d.exr|3",
+		"foo.%04d.exr 1000|foo.%d.exr foo.%04d.exr|foo.%04d.exr|1",
+	}
+	result := []string{}
+	for _, decision := range builder.Decisions() {
+		result = append(result, fmt.Sprint(decision.Items.String(), "|", strings.Join(decision.Candidates, " "),
+			"|", decision.Chosen, "|", decision.Rule))
+		if decision.Reason == "" {
+			t.Error("decision", decision, "has no reason")
+		}
+	}
+	if !testEq(result, expected) {
+		t.Error("decisions were", result, "Should Be:", expected)
+	}
+
+	seqs, decisions := RegroupSequences(SequencesFromStringSlice([]string{"foo.100.exr", "foo.99.exr"}), nil, POLICY_SPLIT)
+	if len(seqs) != 2 || seqs[0].String() != "foo.%d.exr 99" || seqs[1].String() != "foo.%03d.exr 100" ||
+		len(decisions) != 2 || decisions[0].Chosen != "" || decisions[0].Apart != "foo.%d.exr" || decisions[1].Rule != 0 {
+		t.Error("split regrouped as", seqs, decisions)
+	}
+}
+
+// benchNames generates n names resembling a sim cache directory: a few long sequences, some
+// with gaps, and a sprinkling of single files.
+func benchNames(n int) []string {
+	names := make([]string, 0, n)
+	for i := 0; len(names) < n; i++ {
+		switch i % 10 {
+		case 0:
+			names = append(names, "notes_"+strconv.Itoa(i)+".txt")
+		case 1, 2, 3:
+			names = append(names, fmt.Sprintf("fluid_v003.%07d.bgeo.sc", i))
+		case 4, 5, 6:
+			if i%1000 != 4 {
+				names = append(names, fmt.Sprintf("particles.%d.vdb", i))
+			}
+		default:
+			names = append(names, fmt.Sprintf("render_beauty.%04d.exr", i))
+		}
+	}
+	return names
+}
+
+// benchmarkCollapse times collapsing and formatting n names held in memory. See the README
+// for the wall time and allocation targets.
+func benchmarkCollapse(b *testing.B, n int) {
+	names := benchNames(n)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		RangeStringsFromSequences(SequencesFromStringSlice(names))
+	}
+}
+
+func BenchmarkGroup_Collapse10k(b *testing.B)  { benchmarkCollapse(b, 10000) }
+func BenchmarkGroup_Collapse100k(b *testing.B) { benchmarkCollapse(b, 100000) }
+func BenchmarkGroup_Collapse1M(b *testing.B)   { benchmarkCollapse(b, 1000000) }
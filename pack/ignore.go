@@ -0,0 +1,282 @@
+package lss
+
+/*
+ignore provides support for .lssignore files, which use gitignore syntax to hide clutter
+(eg .DS_Store, Thumbs.db, *.tmp, .nfs*) from listings. The rules which apply to a directory
+are gathered from, in increasing order of precedence:
+
+- the global ignore file in the user's config directory (eg ~/.config/lss/ignore)
+- the .lssignore files of each of the directory's ancestors, from the root down
+- the .lssignore file of the directory itself
+
+As with gitignore, the last rule matching an entry decides whether it is ignored, and
+patterns containing a "/" are anchored to the directory holding the .lssignore file. Unlike
+gitignore, rules only ever apply to the entry being listed: ignoring a directory does not
+hide its contents when that directory is listed explicitly.
+*/
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// IgnoreFileName is the name of the per-directory ignore file.
+const IgnoreFileName = ".lssignore"
+
+//-------------------------
+// Type IgnoreFile
+//-------------------------
+
+// ignoreRule is a single compiled line of an ignore file.
+type ignoreRule struct {
+	pattern string
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// IgnoreFile holds the rules read from a single ignore file. Base is the directory the rules
+// are relative to; an empty Base makes the rules relative to whichever directory is listed,
+// as is the case for the global ignore file.
+type IgnoreFile struct {
+	Base  string
+	rules []ignoreRule
+}
+
+// ParseIgnore reads gitignore style rules from r, relative to the directory base.
+func ParseIgnore(base string, r io.Reader) (error, *IgnoreFile) {
+	ignore := &IgnoreFile{Base: base}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := trimIgnoreLine(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		rule := ignoreRule{pattern: line}
+		if line[0] == '!' {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		re, err := regexp.Compile(ignorePatternToRegex(line))
+		if err != nil {
+			return errors.New("bad ignore pattern '" + rule.pattern + "': " + err.Error()), nil
+		}
+		rule.re = re
+		ignore.rules = append(ignore.rules, rule)
+	}
+	return scanner.Err(), ignore
+}
+
+//-------------------------
+// Type Ignore
+//-------------------------
+
+// Ignore is an ordered collection of IgnoreFiles, lowest precedence first.
+type Ignore struct {
+	Files []*IgnoreFile
+}
+
+// LoadIgnore gathers the global ignore file and the .lssignore files of dir and each of its
+// ancestors. Missing files are skipped; unreadable or malformed ones are reported.
+func LoadIgnore(dir string) (error, *Ignore) {
+	ignore := new(Ignore)
+	if global := GlobalIgnorePath(); global != "" {
+		err, file := readIgnoreFile("", global)
+		if err != nil {
+			return err, ignore
+		}
+		if file != nil {
+			ignore.Files = append(ignore.Files, file)
+		}
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err, ignore
+	}
+	// collect the directory and its ancestors, then walk them from the root down
+	dirs := []string{}
+	for d := abs; ; d = filepath.Dir(d) {
+		dirs = append(dirs, d)
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		err, file := readIgnoreFile(dirs[i], filepath.Join(dirs[i], IgnoreFileName))
+		if err != nil {
+			return err, ignore
+		}
+		if file != nil {
+			ignore.Files = append(ignore.Files, file)
+		}
+	}
+	return nil, ignore
+}
+
+// GlobalIgnorePath returns the path of the user's global ignore file, or "" if the user's
+// config directory cannot be determined.
+func GlobalIgnorePath() string {
+	config, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(config, "lss", "ignore")
+}
+
+// Ignored returns true if the entry name within dir is ignored. isDir is only consulted when
+// a directory-only rule (eg "cache/") matches, and may be nil if the entry is known not to
+// be a directory.
+func (ig *Ignore) Ignored(dir string, name string, isDir func() bool) bool {
+	return ig.ignored(ig.prefixes(dir), name, isDir)
+}
+
+// Filter returns a Filter rejecting the entries of dir which are ignored.
+func (ig *Ignore) Filter(dir string) Filter {
+	prefixes := ig.prefixes(dir)
+	return func(name string) bool {
+		return !ig.ignored(prefixes, name, func() bool {
+			info, err := os.Stat(filepath.Join(dir, name))
+			return err == nil && info.IsDir()
+		})
+	}
+}
+
+// prefixes returns, for each of the IgnoreFiles, the slash separated path of dir relative
+// to the file's Base (eg "shot1/render/"), or "-" if dir is not beneath the Base.
+func (ig *Ignore) prefixes(dir string) []string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		abs = dir
+	}
+	prefixes := make([]string, len(ig.Files))
+	for i, file := range ig.Files {
+		if file.Base == "" {
+			continue
+		}
+		rel, err := filepath.Rel(file.Base, abs)
+		switch {
+		case err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)):
+			prefixes[i] = "-"
+		case rel != ".":
+			prefixes[i] = filepath.ToSlash(rel) + "/"
+		}
+	}
+	return prefixes
+}
+
+// ignored applies the rules of each IgnoreFile in turn, the last matching rule winning.
+func (ig *Ignore) ignored(prefixes []string, name string, isDir func() bool) bool {
+	ignored := false
+	for i, file := range ig.Files {
+		if prefixes[i] == "-" {
+			continue
+		}
+		rel := prefixes[i] + name
+		for _, rule := range file.rules {
+			if !rule.re.MatchString(rel) {
+				continue
+			}
+			if rule.dirOnly && (isDir == nil || !isDir()) {
+				continue
+			}
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+//-----------------------------------------
+// Private Utility Functions
+//-----------------------------------------
+
+// readIgnoreFile parses the ignore file at path, returning a nil IgnoreFile if it does not
+// exist.
+func readIgnoreFile(base string, path string) (error, *IgnoreFile) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return err, nil
+	}
+	defer f.Close()
+	err, file := ParseIgnore(base, f)
+	if err != nil {
+		return errors.New(path + ": " + err.Error()), nil
+	}
+	return nil, file
+}
+
+// trimIgnoreLine strips trailing whitespace from a line of an ignore file, unless it is
+// escaped with a backslash.
+func trimIgnoreLine(line string) string {
+	line = strings.TrimRight(line, "\r")
+	for len(line) > 0 && (line[len(line)-1] == ' ' || line[len(line)-1] == '\t') {
+		if len(line) > 1 && line[len(line)-2] == '\\' {
+			return line[:len(line)-2] + line[len(line)-1:]
+		}
+		line = line[:len(line)-1]
+	}
+	return line
+}
+
+// ignorePatternToRegex converts a gitignore style glob into an anchored regular expression
+// matching slash separated paths relative to the ignore file's directory.
+func ignorePatternToRegex(pattern string) string {
+	// patterns without a slash match at any depth, the rest are anchored
+	prefix := "(?:.*/)?"
+	if strings.Contains(pattern, "/") {
+		prefix = ""
+		pattern = strings.TrimPrefix(pattern, "/")
+	}
+
+	expr := ""
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			expr += "(?:.*/)?"
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			expr += ".*"
+			i++
+		case c == '*':
+			expr += "[^/]*"
+		case c == '?':
+			expr += "[^/]"
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			expr += regexp.QuoteMeta(pattern[i : i+1])
+		case c == '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				expr += `\[`
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr += "[" + strings.ReplaceAll(class, `\`, `\\`) + "]"
+			i += end + 1
+		default:
+			expr += regexp.QuoteMeta(pattern[i : i+1])
+		}
+	}
+	return "^" + prefix + expr + "$"
+}
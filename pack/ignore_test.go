@@ -0,0 +1,94 @@
+package lss
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIgnore_Patterns(t *testing.T) {
+	rules := `
+# clutter
+.DS_Store
+Thumbs.db
+*.tmp
+.nfs*
+!keep.tmp
+cache/
+/top.txt
+shot1/render/*.bak
+**/scratch
+trailing\ 
+`
+	err, file := ParseIgnore("/show", strings.NewReader(rules))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ignore := &Ignore{Files: []*IgnoreFile{file}}
+	isDir := func() bool { return true }
+
+	tests := []struct {
+		dir       string
+		name      string
+		directory bool
+		ignored   bool
+	}{
+		{"/show", ".DS_Store", false, true},
+		{"/show/shot1", "Thumbs.db", false, true},
+		{"/show/shot1", "foo.0001.exr.tmp", false, true},
+		{"/show/shot1", "keep.tmp", false, false},
+		{"/show", ".nfs000123", false, true},
+		{"/show", "cache", true, true},
+		{"/show", "cache", false, false},
+		{"/show", "top.txt", false, true},
+		{"/show/shot1", "top.txt", false, false},
+		{"/show/shot1/render", "foo.bak", false, true},
+		{"/show/shot2/render", "foo.bak", false, false},
+		{"/show/a/b", "scratch", false, true},
+		{"/show", "trailing ", false, true},
+		{"/elsewhere", ".DS_Store", false, false},
+		{"/show", "foo.0001.exr", false, false},
+	}
+	for _, test := range tests {
+		var fn func() bool
+		if test.directory {
+			fn = isDir
+		}
+		if ignore.Ignored(test.dir, test.name, fn) != test.ignored {
+			t.Error(test.dir, test.name, "ignored should be", test.ignored)
+		}
+	}
+}
+
+func TestIgnore_LoadIgnore(t *testing.T) {
+	root := t.TempDir()
+	config := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", config)
+	t.Setenv("HOME", config)
+	os.MkdirAll(filepath.Dir(GlobalIgnorePath()), 0755)
+
+	shot := filepath.Join(root, "shot1")
+	if err := os.Mkdir(shot, 0755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(GlobalIgnorePath(), []byte("Thumbs.db\n"), 0644)
+	os.WriteFile(filepath.Join(root, IgnoreFileName), []byte("*.tmp\n"), 0644)
+	os.WriteFile(filepath.Join(shot, IgnoreFileName), []byte("!keep.tmp\n"), 0644)
+	for _, name := range []string{"Thumbs.db", "a.tmp", "keep.tmp", "foo.0001.exr"} {
+		os.WriteFile(filepath.Join(shot, name), []byte{}, 0644)
+	}
+
+	err, ignore := LoadIgnore(shot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err, names := FilteredListingFromPath(shot, HiddenFilter(false).And(ignore.Filter(shot)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	Stringlist(names).NaturalSort()
+	if expected := []string{"foo.0001.exr", "keep.tmp"}; !testEq(names, expected) {
+		t.Error("listing was", names, "Should Be:", expected)
+	}
+}
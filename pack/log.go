@@ -0,0 +1,50 @@
+package lss
+
+/*
+log provides the package's structured logger. Nothing is logged until SetLogger is called,
+and logging which would be discarded costs next to nothing, so that listings are unaffected
+unless debugging is requested. Messages are logged at:
+
+Info  - one summary per stage (eg a directory read, a directory grouped)
+Debug - one record per entry, explaining how it was filtered, classified and grouped
+*/
+
+import (
+	"context"
+	"log/slog"
+)
+
+// logger is the package's logger, which discards everything until SetLogger is called.
+var logger = slog.New(discardHandler{})
+
+// SetLogger directs the package's log output to l. A nil l discards it. SetLogger should be
+// called before listing begins.
+func SetLogger(l *slog.Logger) {
+	if l == nil {
+		l = slog.New(discardHandler{})
+	}
+	logger = l
+}
+
+// Logger returns the package's logger.
+func Logger() *slog.Logger {
+	return logger
+}
+
+//-----------------------------------------
+// Private Utility Types & Functions
+//-----------------------------------------
+
+// debugEnabled returns true if per entry Debug records are wanted. Callers logging in loops
+// should check it once, before the loop, rather than building records to be discarded.
+func debugEnabled() bool {
+	return logger.Enabled(context.Background(), slog.LevelDebug)
+}
+
+// discardHandler is an slog.Handler which is never enabled.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
@@ -0,0 +1,62 @@
+package lss
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// captureLog directs the package's log output at level and above to a buffer for the
+// duration of the test.
+func captureLog(t *testing.T, level slog.Level) *bytes.Buffer {
+	buf := new(bytes.Buffer)
+	SetLogger(slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: level})))
+	t.Cleanup(func() { SetLogger(nil) })
+	return buf
+}
+
+func TestLog_ExplainsGrouping(t *testing.T) {
+	buf := captureLog(t, slog.LevelDebug)
+	SequencesFromStringSlice([]string{"foo.9.exr", "foo.10.exr", "bar.0001.exr", "bar.1000.exr", "foo.12abc"})
+
+	for _, expected := range []string{
+		`msg=classified name=foo.9.exr padding=unpadded reason="single digit frame number"`,
+		`msg=classified name=bar.0001.exr padding=padded reason="frame number has a leading zero"`,
+		`msg=classified name=foo.10.exr padding=either reason="frame number has 2 digits and no leading zero"`,
+		`msg="resolved either" prefix=foo extension=.exr width=2 frames=1 as=unpadded reason="family holds single digit frames"`,
+		`msg="resolved either" prefix=bar extension=.exr width=4 frames=1 as=padded reason="family holds zero padded frames of the same width"`,
+		`msg="single file" name=foo.12abc reason="name does not end with the frame number and extension"`,
+		`msg=grouped families=2 singles=1 sequences=2`,
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Error("log did not contain", expected, "\nlog:\n"+buf.String())
+		}
+	}
+}
+
+func TestLog_ExplainsSplitFamilies(t *testing.T) {
+	buf := captureLog(t, slog.LevelDebug)
+	SequencesFromStringSlice([]string{"foo.1.exr", "foo.0002.exr"})
+	if !strings.Contains(buf.String(), `msg="family split" prefix=foo extension=.exr`) {
+		t.Error("log did not explain the split family\nlog:\n" + buf.String())
+	}
+}
+
+func TestLog_LevelsAndDiscard(t *testing.T) {
+	buf := captureLog(t, slog.LevelInfo)
+	dir := makeListing(t, []string{"foo.0001.exr", ".hidden"})
+	CollapsePath(dir, HiddenFilter(false))
+	if strings.Contains(buf.String(), "level=DEBUG") {
+		t.Error("Debug records logged at Info level\nlog:\n" + buf.String())
+	}
+	if !strings.Contains(buf.String(), `msg="read directory"`) || !strings.Contains(buf.String(), "entries=2 kept=1") {
+		t.Error("directory read was not summarized\nlog:\n" + buf.String())
+	}
+
+	SetLogger(nil)
+	if debugEnabled() || Logger().Enabled(context.Background(), slog.LevelError) {
+		t.Error("a nil logger should discard everything")
+	}
+}
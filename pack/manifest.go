@@ -0,0 +1,322 @@
+package lss
+
+/*
+manifest provides the types and functions needed to generate and verify delivery manifests.
+A Manifest records the MD5 and SHA-256 of every member of each collapsed Sequence in a
+directory, along with a combined per-sequence digest. The combined digest is the SHA-256 of
+the sequence's members rendered in sha256sum form, in frame order:
+
+<sha256>  foo.0001.exr
+<sha256>  foo.0002.exr
+
+Manifests may be written as JSON, or in a sha256sum compatible text format.
+*/
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+//---------------------------
+// Manifest Types
+//---------------------------
+
+// ManifestFrame records the checksums of a single file.
+type ManifestFrame struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	MD5    string `json:"md5,omitempty"`
+	SHA256 string `json:"sha256"`
+}
+
+// ManifestSequence records the checksums of each member of a collapsed Sequence, in frame
+// order, along with the combined digest of the Sequence.
+type ManifestSequence struct {
+	Pattern string          `json:"pattern"`
+	Ranges  string          `json:"ranges,omitempty"`
+	Digest  string          `json:"sha256"`
+	Frames  []ManifestFrame `json:"frames"`
+}
+
+// Manifest records the checksums of the contents of a directory, grouped by Sequence.
+type Manifest struct {
+	Root      string             `json:"root"`
+	Sequences []ManifestSequence `json:"sequences"`
+}
+
+// VerifyReport records the outcome of checking a directory against a Manifest. Mismatched
+// and Missing are collapsed into Sequences so that they may be reported in range form.
+type VerifyReport struct {
+	Checked    int
+	Mismatched []Sequence
+	Missing    []Sequence
+}
+
+// OK returns true if every file in the Manifest was found and matched.
+func (r *VerifyReport) OK() bool {
+	return len(r.Mismatched) == 0 && len(r.Missing) == 0
+}
+
+//-----------------------------
+// Manifest Functions
+//-----------------------------
+
+// BuildManifest hashes each of the named entries of the directory at path, using at most
+// workers goroutines, and returns a Manifest grouping the results by Sequence. Names are
+// expected to be relative to path, as returned by FilteredListingFromPath. Entries which are
+// not regular files (eg directories) are left out of the Manifest. The first error
+// encountered while reading the files is returned.
+func BuildManifest(path string, names []string, workers int) (error, *Manifest) {
+	manifest := &Manifest{Root: path, Sequences: []ManifestSequence{}}
+
+	var err error
+	hashed := map[string]ManifestFrame{}
+	regular := []string{}
+	// drain every result, even after a failure, so that the workers may exit
+	for result := range hashFiles(path, names, workers) {
+		switch {
+		case result.err != nil:
+			if err == nil {
+				err = result.err
+			}
+		case !result.skipped:
+			hashed[result.frame.Name] = result.frame
+			regular = append(regular, result.frame.Name)
+		}
+	}
+	if err != nil {
+		return err, manifest
+	}
+
+	for _, seq := range SequencesFromStringSlice(regular) {
+		mseq := ManifestSequence{
+			Pattern: seq.Pattern(),
+			Ranges:  seq.Ranges(),
+			Frames:  make([]ManifestFrame, 0, seq.Len()),
+		}
+		for _, name := range seq.Names() {
+			mseq.Frames = append(mseq.Frames, hashed[name])
+		}
+		mseq.Digest = sequenceDigest(mseq.Frames)
+		manifest.Sequences = append(manifest.Sequences, mseq)
+	}
+	return nil, manifest
+}
+
+// ReadManifest reads a Manifest written by either WriteJSON or WriteSums. Manifests read
+// from the sha256sum format carry neither sizes nor MD5 checksums.
+func ReadManifest(r io.Reader) (error, *Manifest) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err, nil
+	}
+
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		manifest := new(Manifest)
+		if err := json.Unmarshal(trimmed, manifest); err != nil {
+			return err, nil
+		}
+		return nil, manifest
+	}
+
+	// sha256sum format: "<hex>  <name>", or "<hex> *<name>" for binary mode
+	sums := map[string]string{}
+	names := []string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if len(line) < 67 || line[64] != ' ' || (line[65] != ' ' && line[65] != '*') {
+			return fmt.Errorf("manifest line %d is not in sha256sum format", lineno), nil
+		}
+		name := line[66:]
+		sums[name] = strings.ToLower(line[:64])
+		names = append(names, name)
+	}
+	if err := scanner.Err(); err != nil {
+		return err, nil
+	}
+
+	manifest := &Manifest{Sequences: []ManifestSequence{}}
+	for _, seq := range SequencesFromStringSlice(names) {
+		mseq := ManifestSequence{Pattern: seq.Pattern(), Ranges: seq.Ranges()}
+		for _, name := range seq.Names() {
+			mseq.Frames = append(mseq.Frames, ManifestFrame{Name: name, SHA256: sums[name]})
+		}
+		mseq.Digest = sequenceDigest(mseq.Frames)
+		manifest.Sequences = append(manifest.Sequences, mseq)
+	}
+	return nil, manifest
+}
+
+// VerifyManifest re-hashes the files recorded in the Manifest, relative to the supplied
+// directory, and reports any which are missing or whose checksums no longer match.
+func VerifyManifest(path string, manifest *Manifest, workers int) (error, *VerifyReport) {
+	report := new(VerifyReport)
+	expected := map[string]ManifestFrame{}
+	names := []string{}
+	for _, mseq := range manifest.Sequences {
+		for _, frame := range mseq.Frames {
+			expected[frame.Name] = frame
+			names = append(names, frame.Name)
+		}
+	}
+
+	var err error
+	mismatched := []string{}
+	missing := []string{}
+	for result := range hashFiles(path, names, workers) {
+		report.Checked++
+		switch {
+		case result.err != nil && os.IsNotExist(result.err):
+			missing = append(missing, result.frame.Name)
+		case result.err != nil:
+			if err == nil {
+				err = result.err
+			}
+		case result.skipped:
+			mismatched = append(mismatched, result.frame.Name)
+		default:
+			want := expected[result.frame.Name]
+			if want.SHA256 != result.frame.SHA256 ||
+				(want.MD5 != "" && want.MD5 != result.frame.MD5) {
+				mismatched = append(mismatched, result.frame.Name)
+			}
+		}
+	}
+
+	report.Mismatched = SequencesFromStringSlice(mismatched)
+	report.Missing = SequencesFromStringSlice(missing)
+	return err, report
+}
+
+//-------------------------
+// Manifest Methods
+//-------------------------
+
+// WriteJSON writes the Manifest to w as indented JSON.
+func (m *Manifest) WriteJSON(w io.Writer) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// WriteSums writes the Manifest to w in the format produced by sha256sum, so that it may
+// be checked with `sha256sum -c` as well as `lss verify`.
+func (m *Manifest) WriteSums(w io.Writer) error {
+	for _, mseq := range m.Sequences {
+		for _, frame := range mseq.Frames {
+			if _, err := fmt.Fprintf(w, "%s  %s\n", frame.SHA256, frame.Name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//-----------------------------------------
+// Private Utility Functions & Types
+//-----------------------------------------
+
+// hashResult is published by hashFiles for each file it is handed.
+type hashResult struct {
+	frame   ManifestFrame
+	skipped bool // not a regular file
+	err     error
+}
+
+// hashFiles hashes the named files in dir using at most workers goroutines. A result is
+// published on the returned channel for each name, in no particular order. The channel is
+// closed once every name has been handled.
+func hashFiles(dir string, names []string, workers int) chan hashResult {
+	if workers < 1 {
+		workers = 1
+	}
+	jobs := make(chan string)
+	results := make(chan hashResult)
+
+	go func() {
+		for _, name := range names {
+			jobs <- name
+		}
+		close(jobs)
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range jobs {
+				results <- hashFile(dir, name)
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// hashFile computes the MD5 and SHA-256 checksums of a single file.
+func hashFile(dir string, name string) hashResult {
+	result := hashResult{frame: ManifestFrame{Name: name}}
+	fpath := filepath.Join(dir, name)
+
+	info, err := os.Stat(fpath)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	if !info.Mode().IsRegular() {
+		result.skipped = true
+		return result
+	}
+
+	f, err := os.Open(fpath)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	defer f.Close()
+
+	md5h := md5.New()
+	shah := sha256.New()
+	size, err := io.Copy(io.MultiWriter(md5h, shah), f)
+	if err != nil {
+		result.err = errors.New("unable to read '" + fpath + "': " + err.Error())
+		return result
+	}
+	result.frame.Size = size
+	result.frame.MD5 = hex.EncodeToString(md5h.Sum(nil))
+	result.frame.SHA256 = hex.EncodeToString(shah.Sum(nil))
+	return result
+}
+
+// sequenceDigest computes the combined digest of a sequence's frames.
+func sequenceDigest(frames []ManifestFrame) string {
+	h := sha256.New()
+	for _, frame := range frames {
+		fmt.Fprintf(h, "%s  %s\n", frame.SHA256, frame.Name)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
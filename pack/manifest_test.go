@@ -0,0 +1,120 @@
+package lss
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeManifestFixture(t *testing.T, dir string, names ...string) {
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestManifest_BuildManifest(t *testing.T) {
+	dir := t.TempDir()
+	writeManifestFixture(t, dir, "foo.0001.exr", "foo.0002.exr", "foo.0003.exr", "notes.txt")
+	if err := os.Mkdir(filepath.Join(dir, "subdir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	err, names := FilteredListingFromPath(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err, manifest := BuildManifest(dir, names, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Sequences) != 2 {
+		t.Fatal("Wrong number of sequences:", len(manifest.Sequences), manifest.Sequences)
+	}
+	foo := manifest.Sequences[0]
+	if foo.Pattern != "foo.%04d.exr" || foo.Ranges != "1-3" || len(foo.Frames) != 3 {
+		t.Error("Wrong sequence recorded:", foo.Pattern, foo.Ranges, len(foo.Frames))
+	}
+	// sha256 of "foo.0001.exr"
+	if foo.Frames[0].SHA256 != "36933b3b92ec42d8991cea82337d546034f251bdc8b2802328e9af47e843f2c5" {
+		t.Error("Bad sha256:", foo.Frames[0].SHA256)
+	}
+	if foo.Frames[0].Size != int64(len("foo.0001.exr")) {
+		t.Error("Wrong size recorded:", foo.Frames[0].Size)
+	}
+}
+
+func TestManifest_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	writeManifestFixture(t, dir, "foo.0001.exr", "foo.0002.exr", "bar.1.dpx", "bar.2.dpx")
+	err, names := FilteredListingFromPath(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err, manifest := BuildManifest(dir, names, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, format := range []string{"json", "sha256"} {
+		buf := new(bytes.Buffer)
+		if format == "json" {
+			err = manifest.WriteJSON(buf)
+		} else {
+			err = manifest.WriteSums(buf)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		err, read := ReadManifest(buf)
+		if err != nil {
+			t.Fatal(format, err)
+		}
+		for i := range manifest.Sequences {
+			if read.Sequences[i].Digest != manifest.Sequences[i].Digest {
+				t.Error(format, "digest changed for", manifest.Sequences[i].Pattern)
+			}
+		}
+		err, report := VerifyManifest(dir, read, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !report.OK() || report.Checked != 4 {
+			t.Error(format, "verification of an untouched directory failed:", report)
+		}
+	}
+}
+
+func TestManifest_VerifyReportsRanges(t *testing.T) {
+	dir := t.TempDir()
+	writeManifestFixture(t, dir, "foo.0001.exr", "foo.0002.exr", "foo.0003.exr",
+		"foo.0004.exr", "foo.0005.exr")
+	err, names := FilteredListingFromPath(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err, manifest := BuildManifest(dir, names, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "foo.0002.exr"), []byte("changed"), 0644)
+	os.WriteFile(filepath.Join(dir, "foo.0003.exr"), []byte("changed"), 0644)
+	os.Remove(filepath.Join(dir, "foo.0005.exr"))
+
+	err, report := VerifyManifest(dir, manifest, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.OK() {
+		t.Fatal("verification should have failed")
+	}
+	if len(report.Mismatched) != 1 || report.Mismatched[0].String() != "foo.%04d.exr 2-3" {
+		t.Error("Wrong mismatches reported:", report.Mismatched)
+	}
+	if len(report.Missing) != 1 || report.Missing[0].String() != "foo.%04d.exr 5" {
+		t.Error("Wrong missing frames reported:", report.Missing)
+	}
+}
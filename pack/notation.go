@@ -0,0 +1,140 @@
+package lss
+
+/*
+notation provides the ways in which a sequence's pattern and frames may be written, as
+different packages expect different forms:
+
+printf  - foo.%04d.exr, foo.%d.exr (the default; nuke, ffmpeg)
+hash    - foo.####.exr, foo.#.exr  (nuke, shake)
+at      - foo.@@@@.exr, foo.@.exr  (katana, clarisse)
+houdini - foo.$F4.exr, foo.$F.exr
+rv      - foo.1-100#.exr, foo.1-100@@@.exr, foo.1-100@.exr (rv; # stands for four digits, and
+          each @ for one). A pattern without a range is written as at writes it, since a # without
+          a range would be read as a single digit.
+
+nuke is accepted as another name for printf, and maya for hash.
+
+A Style combines a Notation with the separators used to write frame ranges, which default to
+"-" within a run of frames and "," between runs (eg 1-3,5).
+*/
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+//-------------------------
+// Type Notation
+//-------------------------
+
+// Notation is a way of writing the frame number within a sequence's pattern.
+type Notation int
+
+const (
+	NOTATION_PRINTF  Notation = iota // %04d
+	NOTATION_HASH                    // ####
+	NOTATION_HOUDINI                 // $F4
+	NOTATION_AT                      // @@@@
+	NOTATION_RV                      // 1-100#
+)
+
+var notationNames = []string{"printf", "hash", "houdini", "at", "rv"}
+
+// notationAliases are the other names ParseNotation accepts, after the packages which use them.
+var notationAliases = map[string]Notation{"nuke": NOTATION_PRINTF, "maya": NOTATION_HASH}
+
+// ParseNotation returns the Notation named by name, one of printf, hash, at, houdini or rv, or
+// one of their aliases.
+func ParseNotation(name string) (error, Notation) {
+	for i, notationName := range notationNames {
+		if name == notationName {
+			return nil, Notation(i)
+		}
+	}
+	if notation, ok := notationAliases[name]; ok {
+		return nil, notation
+	}
+	return errors.New("unknown notation:'" + name + "' (use " + strings.Join(notationNames, ", ") + ")"), NOTATION_PRINTF
+}
+
+// String returns the name of the notation, as accepted by ParseNotation.
+func (n Notation) String() string {
+	if n < 0 || int(n) >= len(notationNames) {
+		return "unknown"
+	}
+	return notationNames[n]
+}
+
+// Token returns the notation's stand in for a frame number of the given padding (eg %04d).
+// A padding of 1 or less denotes an unpadded frame number. For rv, the range of frames which
+// precedes the token, and with it the # form, is left to Style.Pattern.
+func (n Notation) Token(padding int) string {
+	switch n {
+	case NOTATION_HASH:
+		if padding <= 1 {
+			return "#"
+		}
+		return strings.Repeat("#", padding)
+	case NOTATION_AT, NOTATION_RV:
+		if padding <= 1 {
+			return "@"
+		}
+		return strings.Repeat("@", padding)
+	case NOTATION_HOUDINI:
+		if padding <= 1 {
+			return "$F"
+		}
+		return "$F" + strconv.Itoa(padding)
+	}
+	if padding <= 1 {
+		return "%d"
+	}
+	return "%0" + strconv.Itoa(padding) + "d"
+}
+
+//-------------------------
+// Type Style
+//-------------------------
+
+// Style determines how Sequences are written. The zero Style writes them as Sequence.Pattern
+// and Sequence.Ranges do.
+type Style struct {
+	Notation Notation
+	RangeSep string // separates the first and last frames of a run; "-" if empty
+	ListSep  string // separates runs; "," if empty
+}
+
+// Pattern returns the pattern of seq written in the style's notation, or the name of the file
+// for single files. In rv notation, the token is preceded by the first and last frames, if
+// seq has any.
+func (st Style) Pattern(seq *Sequence) string {
+	if seq.Single() {
+		return seq.Prefix
+	}
+	token := st.Notation.Token(seq.Padding)
+	if st.Notation == NOTATION_RV && len(seq.Frames) > 0 {
+		if seq.Padding == 4 {
+			token = "#"
+		}
+		token = strconv.Itoa(seq.First()) + "-" + strconv.Itoa(seq.Last()) + token
+	}
+	pattern := seq.Prefix + "." + token
+	if seq.Extension != "" && seq.Extension[0] != '.' {
+		pattern += "."
+	}
+	return pattern + seq.Extension
+}
+
+// Ranges returns frames, which must be ascending, in condensed range form using the style's
+// separators.
+func (st Style) Ranges(frames []int) string {
+	rangeSep, listSep := st.RangeSep, st.ListSep
+	if rangeSep == "" {
+		rangeSep = "-"
+	}
+	if listSep == "" {
+		listSep = ","
+	}
+	return formatRanges(frames, rangeSep, listSep)
+}
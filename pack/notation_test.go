@@ -0,0 +1,57 @@
+package lss
+
+import (
+	"testing"
+)
+
+func TestNotation_Pattern(t *testing.T) {
+	seqs := SequencesFromStringSlice([]string{"foo.0001.exr", "foo.0002.exr", "bar.1", "bar.2", "notes.txt"})
+	expected := map[string][]string{
+		"printf":  {"bar.%d", "foo.%04d.exr", "notes.txt"},
+		"hash":    {"bar.#", "foo.####.exr", "notes.txt"},
+		"houdini": {"bar.$F", "foo.$F4.exr", "notes.txt"},
+		"at":      {"bar.@", "foo.@@@@.exr", "notes.txt"},
+		"rv":      {"bar.1-2@", "foo.1-2#.exr", "notes.txt"},
+	}
+	for name, patterns := range expected {
+		err, notation := ParseNotation(name)
+		if err != nil || notation.String() != name {
+			t.Fatal("ParseNotation(", name, ") returned", notation, err)
+		}
+		for i := range seqs {
+			if pattern := (Style{Notation: notation}).Pattern(&seqs[i]); pattern != patterns[i] {
+				t.Error(name, "pattern was", pattern, "Should Be:", patterns[i])
+			}
+		}
+	}
+	for alias, expected := range map[string]Notation{"nuke": NOTATION_PRINTF, "maya": NOTATION_HASH} {
+		if err, notation := ParseNotation(alias); err != nil || notation != expected {
+			t.Error("ParseNotation(", alias, ") returned", notation, err)
+		}
+	}
+	if err, _ := ParseNotation("sprintf"); err == nil {
+		t.Error("ParseNotation should reject unknown notations")
+	}
+	for i := range seqs {
+		if pattern := (Style{}).Pattern(&seqs[i]); pattern != seqs[i].Pattern() {
+			t.Error("the zero Style wrote", pattern, "Should Be:", seqs[i].Pattern())
+		}
+	}
+}
+
+func TestNotation_Ranges(t *testing.T) {
+	frames := []int{1, 2, 3, 5, 7, 8}
+	tests := map[Style]string{
+		{}:                            "1-3,5,7-8",
+		{RangeSep: ":", ListSep: " "}: "1:3 5 7:8",
+		{ListSep: ", "}:               "1-3, 5, 7-8",
+	}
+	for style, expected := range tests {
+		if ranges := style.Ranges(frames); ranges != expected {
+			t.Error(style, "wrote", ranges, "Should Be:", expected)
+		}
+	}
+	if ranges := (Style{}).Ranges(nil); ranges != "" {
+		t.Error("no frames wrote", ranges)
+	}
+}
@@ -0,0 +1,217 @@
+package lss
+
+/*
+pattern provides the parsing of sequence patterns, as written by lss or by hand, and their
+resolution to the files which exist on disk. A pattern may be written in any notation lss
+understands (see notation.go): foo.%04d.exr, foo.####.exr, foo.@@@@.exr, foo.$F4.exr and
+foo.#.exr all denote the same sequence. Patterns in rv notation (eg foo.1-100#.exr) also carry
+a range of frames, and within them, as in rv, each # stands for four digits rather than one.
+
+As with the names lss collapses (see ScanName), the frame number must follow a "." in the last
+component of the pattern, and the extension, if any, must start with one.
+*/
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParsePattern parses pattern, in any notation, into a Sequence. The Sequence holds no frames,
+// unless the pattern is in rv notation, in which case it holds each frame of the pattern's
+// range, which may hold no more than MAX_RANGE_FRAMES frames. Any directory in the pattern is
+// kept in the Sequence's Prefix, so that the names of its members are paths.
+func ParsePattern(pattern string) (error, Sequence) {
+	dir, base := "", pattern
+	if i := strings.LastIndexByte(pattern, filepath.Separator); i >= 0 {
+		dir, base = pattern[:i+1], pattern[i+1:]
+	}
+
+	start, end, padding := -1, -1, 0
+	first, last, ranged := 0, 0, false
+	for i := 0; i < len(base); {
+		tokenStart, rangeFirst, rangeLast, hasRange := scanFrameRange(base, i)
+		tokenEnd, tokenPadding, ok := scanFrameToken(base, tokenStart)
+		if !ok {
+			i++
+			continue
+		}
+		if start >= 0 {
+			return errors.New("ambiguous pattern '" + pattern + "': it holds more than one frame number (" +
+				base[start:end] + " and " + base[i:tokenEnd] + ")"), Sequence{}
+		}
+		if hasRange && base[tokenStart] == '#' {
+			tokenPadding *= 4
+		}
+		start, end, padding = i, tokenEnd, tokenPadding
+		first, last, ranged = rangeFirst, rangeLast, hasRange
+		i = tokenEnd
+	}
+
+	switch {
+	case start < 0:
+		return errors.New("no frame number in pattern '" + pattern + "' (expected eg %04d, ####, @@@@ or $F4)"), Sequence{}
+	case start == 0 || base[start-1] != '.':
+		return errors.New("the frame number of pattern '" + pattern + "' must follow a '.'"), Sequence{}
+	case end < len(base) && base[end] != '.':
+		return errors.New("the extension of pattern '" + pattern + "' must start with a '.'"), Sequence{}
+	case ranged && last < first:
+		return errors.New("the frame range of pattern '" + pattern + "' runs backwards"), Sequence{}
+	case ranged && (last-first+1 > MAX_RANGE_FRAMES || last-first+1 < 0):
+		return tooManyFrames(base[start:end]), Sequence{}
+	}
+	if padding < 1 {
+		padding = 1
+	}
+	seq := Sequence{Prefix: dir + base[:start-1], Padding: padding, Extension: base[end:], Frames: []int{}}
+	for frame := first; ranged && frame <= last; frame++ {
+		seq.Frames = append(seq.Frames, frame)
+	}
+	return nil, seq
+}
+
+// ConvertPattern rewrites pattern, in any notation, in the notation of style. Converting a
+// pattern in rv notation to another drops its range of frames.
+func ConvertPattern(pattern string, style Style) (error, string) {
+	err, seq := ParsePattern(pattern)
+	if err != nil {
+		return err, ""
+	}
+	return nil, style.Pattern(&seq)
+}
+
+// GenerateNames returns the names of the given frames of the sequence denoted by pattern, in
+// the order given, without consulting the filesystem. It is the inverse of collapsing: the
+// names collapse back into the pattern, as BuildRangeStringPrefix writes it, and the frames.
+// (Unless every frame fills the padding, eg foo.%03d.exr 100-200, which could as well be
+// foo.%d.exr; see group.go.)
+func GenerateNames(pattern string, frames []int) (error, []string) {
+	err, seq := ParsePattern(pattern)
+	if err != nil {
+		return err, nil
+	}
+	seq.Frames = frames
+	return nil, seq.Names()
+}
+
+// ExpandPattern resolves pattern to the members of its sequence which exist on disk. Only
+// names which the pattern reproduces exactly are members, so that eg foo.%04d.exr matches
+// foo.0001.exr and foo.10000.exr, but neither foo.1.exr nor foo.001.exr. If frames is nil,
+// every member is returned, unless the pattern is in rv notation, in which case its range
+// stands for frames; otherwise only those frames are, and the frames which do not exist are
+// returned as missing. The returned Sequence holds the frames found, in order.
+func ExpandPattern(pattern string, frames []int) (err error, found Sequence, missing []int) {
+	err, found = ParsePattern(pattern)
+	if err != nil {
+		return err, found, nil
+	}
+	if frames == nil && len(found.Frames) > 0 {
+		frames = found.Frames
+	}
+	found.Frames = []int{}
+	dir, base := filepath.Split(found.Prefix)
+	if dir == "" {
+		dir = "."
+	}
+
+	present := map[int]bool{}
+	err = ReadDirNames(dir, func(name string) bool {
+		return strings.HasPrefix(name, base+".")
+	}, func(name string) {
+		item, exact := ScanName(name)
+		member := DirItem{base, item.Number, found.Padding, found.Extension}
+		if exact && item.Prefix == base && item.Extension == found.Extension && member.String() == name {
+			present[item.Number] = true
+		}
+	})
+	if err != nil {
+		return err, found, nil
+	}
+
+	if frames == nil {
+		for frame := range present {
+			found.Frames = append(found.Frames, frame)
+		}
+		sort.Ints(found.Frames)
+		return nil, found, []int{}
+	}
+	missing = []int{}
+	for _, frame := range frames {
+		if present[frame] {
+			found.Frames = append(found.Frames, frame)
+		} else {
+			missing = append(missing, frame)
+		}
+	}
+	return nil, found, missing
+}
+
+//-----------------------------------------
+// Private Utility Types & Functions
+//-----------------------------------------
+
+// scanFrameRange scans the range of frames which precedes the frame number token of a
+// pattern in rv notation (eg the 1-100 of foo.1-100#.exr), if there is one starting at index
+// i of s. It returns the index of the token, which is i if there is no range, and the first
+// and last frames of the range.
+func scanFrameRange(s string, i int) (int, int, int, bool) {
+	if i > 0 && s[i-1] != '.' {
+		return i, 0, 0, false
+	}
+	dash, first := scanDigits(s, i)
+	if dash == i || dash >= len(s) || s[dash] != '-' {
+		return i, 0, 0, false
+	}
+	token, last := scanDigits(s, dash+1)
+	if token == dash+1 || token >= len(s) || strings.IndexByte("#@%", s[token]) < 0 {
+		return i, 0, 0, false
+	}
+	return token, first, last, true
+}
+
+// scanFrameToken returns the end, and the padding, of the frame number token starting at
+// index i of s, if there is one: %d or %0Nd, a run of # or of @, or $F or $FN.
+func scanFrameToken(s string, i int) (int, int, bool) {
+	switch {
+	case i >= len(s):
+		return 0, 0, false
+	case s[i] == '%':
+		j := i + 1
+		if j < len(s) && s[j] == 'd' {
+			return j + 1, 1, true
+		}
+		if j >= len(s) || s[j] != '0' {
+			return 0, 0, false
+		}
+		end, padding := scanDigits(s, j+1)
+		if end == j+1 || end >= len(s) || s[end] != 'd' {
+			return 0, 0, false
+		}
+		return end + 1, padding, true
+	case s[i] == '#' || s[i] == '@':
+		j := i
+		for j < len(s) && s[j] == s[i] {
+			j++
+		}
+		return j, j - i, true
+	case strings.HasPrefix(s[i:], "$F"):
+		end, padding := scanDigits(s, i+2)
+		if end == i+2 {
+			padding = 1
+		}
+		return end, padding, true
+	}
+	return 0, 0, false
+}
+
+// scanDigits returns the end of the run of digits starting at index i of s, and its value.
+func scanDigits(s string, i int) (int, int) {
+	j := i
+	for j < len(s) && isDigit(s[j]) {
+		j++
+	}
+	value, _ := strconv.Atoi(s[i:j])
+	return j, value
+}
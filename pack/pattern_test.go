@@ -0,0 +1,178 @@
+package lss
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPattern_ParsePattern(t *testing.T) {
+	tests := map[string]Sequence{
+		"foo.%04d.exr":           {"foo", 4, ".exr", []int{}},
+		"foo.####.exr":           {"foo", 4, ".exr", []int{}},
+		"foo.$F4.exr":            {"foo", 4, ".exr", []int{}},
+		"foo.@@@@.exr":           {"foo", 4, ".exr", []int{}},
+		"foo.@.exr":              {"foo", 1, ".exr", []int{}},
+		"foo.%d.exr":             {"foo", 1, ".exr", []int{}},
+		"foo.#.exr":              {"foo", 1, ".exr", []int{}},
+		"foo.$F.exr":             {"foo", 1, ".exr", []int{}},
+		"foo.bar.%03d":           {"foo.bar", 3, "", []int{}},
+		"shot/v001/foo.#.tar.gz": {"shot/v001/foo", 1, ".tar.gz", []int{}},
+	}
+	for pattern, expected := range tests {
+		err, seq := ParsePattern(pattern)
+		if err != nil || !reflect.DeepEqual(seq, expected) {
+			t.Errorf("ParsePattern(%q) returned %v, %v Should Be: %v", pattern, seq, err, expected)
+		}
+	}
+	for _, bad := range []string{"foo.exr", "foo_%04d.exr", "foo.%04dexr", "foo.%04d.####.exr", "foo.##@@.exr", "foo.%4d.exr"} {
+		if err, _ := ParsePattern(bad); err == nil {
+			t.Errorf("ParsePattern(%q) did not fail", bad)
+		}
+	}
+}
+
+func TestPattern_ParsePatternRV(t *testing.T) {
+	tests := map[string]Sequence{
+		"foo.1-3#.exr":      {"foo", 4, ".exr", []int{1, 2, 3}},
+		"foo.1001-1002@@@":  {"foo", 3, "", []int{1001, 1002}},
+		"foo.8-10@.exr":     {"foo", 1, ".exr", []int{8, 9, 10}},
+		"foo.5-6%04d.exr":   {"foo", 4, ".exr", []int{5, 6}},
+		"a.b/foo.1-2#.exr":  {"a.b/foo", 4, ".exr", []int{1, 2}},
+		"shot.010.1-1#.exr": {"shot.010", 4, ".exr", []int{1}},
+	}
+	for pattern, expected := range tests {
+		err, seq := ParsePattern(pattern)
+		if err != nil || !reflect.DeepEqual(seq, expected) {
+			t.Errorf("ParsePattern(%q) returned %v, %v Should Be: %v", pattern, seq, err, expected)
+		}
+	}
+	for _, bad := range []string{"foo.10-1#.exr", "foo.1-10#.1-10#.exr", "foo1-10#.exr", "foo.1-2000000000#.exr"} {
+		if err, _ := ParsePattern(bad); err == nil {
+			t.Errorf("ParsePattern(%q) did not fail", bad)
+		}
+	}
+}
+
+func TestPattern_ConvertPattern(t *testing.T) {
+	notations := map[Notation]string{
+		NOTATION_PRINTF:  "shot/foo.%04d.exr",
+		NOTATION_HASH:    "shot/foo.####.exr",
+		NOTATION_AT:      "shot/foo.@@@@.exr",
+		NOTATION_HOUDINI: "shot/foo.$F4.exr",
+		NOTATION_RV:      "shot/foo.@@@@.exr",
+	}
+	for _, from := range notations {
+		for to, expected := range notations {
+			if err, converted := ConvertPattern(from, Style{Notation: to}); err != nil || converted != expected {
+				t.Errorf("ConvertPattern(%q, %v) returned %q, %v Should Be: %q", from, to, converted, err, expected)
+			}
+		}
+	}
+
+	tests := map[string]string{
+		"foo.1-100#.exr":   "foo.1-100#.exr",
+		"foo.1-100@@@.exr": "foo.1-100@@@.exr",
+		"foo.1-100@.exr":   "foo.1-100@.exr",
+		"foo.%03d.exr":     "foo.@@@.exr",
+		"foo.####.exr":     "foo.@@@@.exr",
+		"foo.%d":           "foo.@",
+	}
+	for from, expected := range tests {
+		if err, converted := ConvertPattern(from, Style{Notation: NOTATION_RV}); err != nil || converted != expected {
+			t.Errorf("ConvertPattern(%q) to rv returned %q, %v Should Be: %q", from, converted, err, expected)
+		}
+	}
+	if err, converted := ConvertPattern("foo.1-100#.exr", Style{Notation: NOTATION_HASH}); err != nil || converted != "foo.####.exr" {
+		t.Error("ConvertPattern from rv to hash returned", converted, err)
+	}
+	if err, _ := ConvertPattern("foo.exr", Style{}); err == nil {
+		t.Error("ConvertPattern accepted a pattern without a frame number")
+	}
+}
+
+func TestPattern_GenerateNames(t *testing.T) {
+	err, frames := ParseFrameRange("1-100x2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pattern := range []string{"foo.%04d.exr", "foo.####.exr", "foo.@@@@.exr", "foo.$F4.exr"} {
+		err, names := GenerateNames(pattern, frames)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(names) != 50 || names[0] != "foo.0001.exr" || names[49] != "foo.0099.exr" {
+			t.Error("GenerateNames(", pattern, ") returned", names)
+		}
+	}
+
+	// the names collapse back into the pattern and frames
+	for _, test := range []struct{ pattern, frames, printf string }{
+		{"foo.####.exr", "1-100x2", "foo.%04d.exr"},
+		{"shot/foo.%d.exr", "8-12", "shot/foo.%d.exr"},
+		{"foo.$F3", "1-3,98-100", "foo.%03d"},
+		{"foo.bar.@@.dpx", "5,7,9-10", "foo.bar.%02d.dpx"},
+	} {
+		_, frames := ParseFrameRange(test.frames)
+		_, names := GenerateNames(test.pattern, frames)
+		seqs := SequencesFromStringSlice(names)
+		if len(seqs) != 1 || seqs[0].Pattern() != test.printf || seqs[0].Ranges() != FrameRangeString(frames) {
+			t.Errorf("%s %s collapsed to %v Should Be: %s %s", test.pattern, test.frames, seqs, test.printf, FrameRangeString(frames))
+			continue
+		}
+		_, parsed := ParsePattern(test.pattern)
+		if prefix := BuildRangeStringPrefix(&DirItem{parsed.Prefix, 0, parsed.Padding, parsed.Extension}); prefix != test.printf {
+			t.Error("BuildRangeStringPrefix of", test.pattern, "returned", prefix)
+		}
+	}
+
+	if err, _ := GenerateNames("foo.exr", frames); err == nil {
+		t.Error("GenerateNames accepted a pattern without a frame number")
+	}
+}
+
+func TestPattern_ExpandPattern(t *testing.T) {
+	dir := makeListing(t, []string{"foo.0001.exr", "foo.0002.exr", "foo.0004.exr", "foo.10000.exr",
+		"foo.1.exr", "foo.003.exr", "foo.0003.dpx", "foobar.0003.exr", "foo.7.exr", "foo.07.exr"})
+
+	err, found, missing := ExpandPattern(filepath.Join(dir, "foo.####.exr"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(found.Frames, []int{1, 2, 4, 10000}) || len(missing) != 0 {
+		t.Error("ExpandPattern found", found.Frames, "and missed", missing)
+	}
+	if names := found.Names(); names[0] != filepath.Join(dir, "foo.0001.exr") {
+		t.Error("ExpandPattern returned the names", names)
+	}
+
+	err, found, missing = ExpandPattern(filepath.Join(dir, "foo.%04d.exr"), []int{1, 2, 3, 4, 5})
+	if err != nil || !reflect.DeepEqual(found.Frames, []int{1, 2, 4}) || !reflect.DeepEqual(missing, []int{3, 5}) {
+		t.Error("ExpandPattern of frames 1-5 found", found.Frames, "and missed", missing, err)
+	}
+
+	err, found, _ = ExpandPattern(filepath.Join(dir, "foo.$F.exr"), nil)
+	if err != nil || !reflect.DeepEqual(found.Frames, []int{1, 7, 10000}) {
+		t.Error("ExpandPattern of an unpadded pattern found", found.Frames, err)
+	}
+
+	err, found, missing = ExpandPattern(filepath.Join(dir, "foo.1-3#.exr"), nil)
+	if err != nil || !reflect.DeepEqual(found.Frames, []int{1, 2}) || !reflect.DeepEqual(missing, []int{3}) {
+		t.Error("ExpandPattern of an rv range found", found.Frames, "and missed", missing, err)
+	}
+	err, found, missing = ExpandPattern(filepath.Join(dir, "foo.1-3#.exr"), []int{1, 2, 3, 4})
+	if err != nil || !reflect.DeepEqual(found.Frames, []int{1, 2, 4}) || !reflect.DeepEqual(missing, []int{3}) {
+		t.Error("ExpandPattern of an rv range with frames found", found.Frames, "and missed", missing, err)
+	}
+
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	os.Chdir(dir)
+	if err, found, _ = ExpandPattern("foo.%03d.exr", nil); err != nil || !reflect.DeepEqual(found.Names(), []string{"foo.003.exr", "foo.10000.exr"}) {
+		t.Error("ExpandPattern in the working directory found", found.Names(), err)
+	}
+	if err, _, _ = ExpandPattern("missing/foo.%04d.exr", nil); err == nil {
+		t.Error("ExpandPattern in a missing directory did not fail")
+	}
+}
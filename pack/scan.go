@@ -0,0 +1,156 @@
+package lss
+
+/*
+scan provides the filename scanner shared by parsing and sorting. A name is walked as a
+series of chunks - maximal runs of digits, and of everything else - which serve both as the
+natural sort key (see NaturalLess) and as the source of the DirItem components (see
+ScanName), so that the two can never disagree about where a number starts and ends.
+
+Neither function allocates: chunks are substrings of the name, and the values of digit
+chunks are accumulated as they are scanned.
+
+ScanName reproduces the rules of the regular expression lss has always used to split names,
+(.*)\.([0-9]+)(\..*)?, which are, for the first line of the name holding a "." followed by a
+digit:
+
+- the number is the last run of digits on that line which follows a "."
+- the prefix is everything on the line before that "."
+- the extension is the rest of the line, provided it starts with a "."
+*/
+
+import (
+	"math"
+	"strings"
+)
+
+//-------------------------
+// Type nameChunk
+//-------------------------
+
+// nameChunk describes a run of digits or non-digits within a name.
+type nameChunk struct {
+	end    int  // index just past the end of the chunk
+	digits bool // true if the chunk is a run of digits
+	// value is the value of a digit chunk; overflow is true if it does not fit in an int
+	value    int
+	overflow bool
+}
+
+// number returns true if the chunk is a run of digits which fits in an int. Chunks which
+// are too big are compared as strings, as they always have been by StringChunksList.
+func (c *nameChunk) number() bool {
+	return c.digits && !c.overflow
+}
+
+// chunkAt scans the chunk of name starting at index start. The empty name is treated as a
+// single empty chunk, matching GenChunks.
+func chunkAt(name string, start int) nameChunk {
+	c := nameChunk{end: start}
+	if start >= len(name) {
+		return c
+	}
+	c.digits = isDigit(name[start])
+	for c.end < len(name) && isDigit(name[c.end]) == c.digits {
+		if c.digits && !c.overflow {
+			d := int(name[c.end] - '0')
+			if c.value > (math.MaxInt-d)/10 {
+				c.overflow = true
+			} else {
+				c.value = c.value*10 + d
+			}
+		}
+		c.end++
+	}
+	return c
+}
+
+//-------------------------
+// Scanning Functions
+//-------------------------
+
+// ScanName splits a directory entry name into the components of a DirItem, exactly as
+// NewDirItemFromString does, in a single pass and without allocating. exact is true if
+// the name carries a number and the DirItem reproduces the name exactly, and so may be
+// collapsed into a sequence.
+func ScanName(name string) (item DirItem, exact bool) {
+	lineStart, lineEnd := 0, len(name)
+	// the candidate number, and the "." before it
+	dot := -1
+	var num nameChunk
+
+	for i := 0; i < len(name); {
+		c := chunkAt(name, i)
+		switch {
+		case c.digits:
+			if i > 0 && name[i-1] == '.' {
+				dot, num = i-1, c
+			}
+		case dot >= 0:
+			// the first line holding a number is the one which matches
+			if nl := strings.IndexByte(name[i:c.end], '\n'); nl >= 0 {
+				lineEnd = i + nl
+				c.end = len(name)
+			}
+		default:
+			if nl := strings.LastIndexByte(name[i:c.end], '\n'); nl >= 0 {
+				lineStart = i + nl + 1
+			}
+		}
+		i = c.end
+	}
+
+	if dot < 0 {
+		return DirItem{Prefix: name, Number: -1, Padding: -1}, false
+	}
+	prefix := name[lineStart:dot]
+	if num.overflow {
+		return DirItem{Prefix: prefix, Number: -1, Padding: -1}, false
+	}
+	item = DirItem{Prefix: prefix, Number: num.value, Padding: num.end - dot - 1}
+	end := num.end
+	if end < lineEnd && name[end] == '.' {
+		item.Extension = name[end:lineEnd]
+		end = lineEnd
+	}
+	return item, lineStart == 0 && end == len(name)
+}
+
+// NaturalLess returns true if lhs sorts before rhs in natural order, as StringChunksList.Less
+// would order them: names are compared chunk by chunk, numbers before other text, shorter
+// numbers before longer ones (so 0010 follows 999), and other text by byte value.
+func NaturalLess(lhs string, rhs string) bool {
+	i, j := 0, 0
+	for {
+		lc, rc := chunkAt(lhs, i), chunkAt(rhs, j)
+		switch {
+		case lc.number() && rc.number():
+			if li, ri := lc.end-i, rc.end-j; li != ri {
+				return li < ri
+			}
+			if lc.value != rc.value {
+				return lc.value < rc.value
+			}
+		case lc.number():
+			return true
+		case rc.number():
+			return false
+		default:
+			if ls, rs := lhs[i:lc.end], rhs[j:rc.end]; ls != rs {
+				return ls < rs
+			}
+		}
+		i, j = lc.end, rc.end
+		if i >= len(lhs) || j >= len(rhs) {
+			return i >= len(lhs) && j < len(rhs)
+		}
+	}
+}
+
+//-----------------------------------------
+// Private Utility Functions
+//-----------------------------------------
+
+// isDigit returns true for the ASCII digits.
+func isDigit(b byte) bool {
+	return '0' <= b && b <= '9'
+}
@@ -0,0 +1,111 @@
+package lss
+
+import (
+	"regexp"
+	"testing"
+)
+
+// scanCorpus holds the names used throughout the tests, along with the awkward cases the
+// scanner has to agree with the regular expression on.
+var scanCorpus = []string{
+	"*RD100_main_robot.0001.exr",
+	".RD100_main_robot_v2.001.mb",
+	".%RD100_main.100.robot_v2.1.exr",
+	"RD100_main.01",
+	"foo.0001.mb",
+	"foo.1.mb",
+	"foo.0100.mb",
+	"foo.1",
+	"foo.001",
+	".foo.0004.mb",
+	"this_is_v01.1000.exr",
+	"this_is_v01.0008.exr",
+	"this_is_v01.11.exr",
+	"shot.010.0001.exr",
+	"shot10.0001.exr",
+	"Shot3.txt",
+	"foo.10.exr.gz",
+	"foo.1.",
+	"foo.12abc",
+	"foo.99999999999999999999.exr",
+	"foo.00000000000000000000001.exr",
+	"foo.0001.exr\n",
+	"a\nb.1.exr",
+	"a.1x\ny.2",
+	"a.b\n.c.1\n.d.2",
+	"\xff.1.\xfe",
+	".1",
+	".",
+	"1",
+	"0001",
+	"",
+}
+
+// regexDirItem splits a name using the regular expression lss parsed names with before the
+// scanner was written.
+var regexDirItemExpr = regexp.MustCompile(`(.*)\.([0-9]+)(\..*){0,1}`)
+
+func regexDirItem(name string) DirItem {
+	results := regexDirItemExpr.FindAllStringSubmatch(name, -1)
+	if len(results) > 0 {
+		return *NewDirItemFromSlice(results[0][1:])
+	}
+	return *NewDirItem(name)
+}
+
+// chunksLess orders two names using StringChunksList.
+func chunksLess(lhs string, rhs string) bool {
+	return StringChunksList{GenChunks(lhs), GenChunks(rhs)}.Less(0, 1)
+}
+
+func checkScanName(t *testing.T, name string) {
+	want := regexDirItem(name)
+	got, exact := ScanName(name)
+	if got != want {
+		t.Errorf("ScanName(%q) was %#v Should Be: %#v", name, got, want)
+	}
+	if wantExact := want.Number >= 0 && want.String() == name; exact != wantExact {
+		t.Errorf("ScanName(%q) returned exact %v Should Be: %v", name, exact, wantExact)
+	}
+}
+
+func checkNaturalLess(t *testing.T, lhs string, rhs string) {
+	if got, want := NaturalLess(lhs, rhs), chunksLess(lhs, rhs); got != want {
+		t.Errorf("NaturalLess(%q, %q) was %v Should Be: %v", lhs, rhs, got, want)
+	}
+}
+
+func TestScan_Corpus(t *testing.T) {
+	for _, lhs := range scanCorpus {
+		checkScanName(t, lhs)
+		for _, rhs := range scanCorpus {
+			checkNaturalLess(t, lhs, rhs)
+		}
+	}
+}
+
+func TestScan_NoAllocations(t *testing.T) {
+	allocs := testing.AllocsPerRun(100, func() {
+		for i, name := range scanCorpus {
+			ScanName(name)
+			NaturalLess(name, scanCorpus[(i+1)%len(scanCorpus)])
+		}
+	})
+	if allocs != 0 {
+		t.Error("scanning allocated", allocs, "times per run. Should Be: 0")
+	}
+}
+
+func FuzzScan_ScanName(f *testing.F) {
+	for _, name := range scanCorpus {
+		f.Add(name)
+	}
+	f.Fuzz(checkScanName)
+}
+
+func FuzzScan_NaturalLess(f *testing.F) {
+	for i, name := range scanCorpus {
+		f.Add(name, scanCorpus[(i+1)%len(scanCorpus)])
+	}
+	f.Fuzz(checkNaturalLess)
+}
@@ -0,0 +1,232 @@
+package lss
+
+/*
+sequence provides the Sequence struct, which models a single collapsed entry of a directory
+listing. Where a DirItemList produced by DivideByType holds one DirItem per file, a Sequence
+holds the shared components once, along with the frame numbers of its members:
+
+foo_bar.0001.mb
+foo_bar.0002.mb
+foo_bar.0004.mb
+
+Becomes:
+Sequence{Prefix: "foo_bar", Padding: 4, Extension: ".mb", Frames: []int{1, 2, 4}}
+*/
+
+import (
+	"github.com/xlab/handysort"
+	"strconv"
+	"strings"
+)
+
+//---------------------------
+// Type Sequence
+//---------------------------
+
+// Sequence represents a run of files sharing a prefix, padding and extension, or a lone
+// file which does not carry a frame number. The latter is flagged by a Padding of -1, in
+// which case Prefix holds the full name and Frames is empty.
+//
+// A Padding of 1 or less denotes an unpadded sequence, whose members may have differing
+// widths (eg foo.9.exr and foo.10.exr).
+type Sequence struct {
+	Prefix    string
+	Padding   int
+	Extension string
+	Frames    []int
+}
+
+//-----------------------------
+// Sequence Constructors
+//-----------------------------
+
+// NewSequenceFromDirItemList builds a Sequence from a homogenous, sorted DirItemList, such
+// as the ones published by DivideByType. Lists whose members disagree on padding are
+// treated as unpadded.
+func NewSequenceFromDirItemList(list DirItemList) Sequence {
+	if len(list) == 0 {
+		return Sequence{Padding: -1}
+	}
+	seq := Sequence{
+		Prefix:    list[0].Prefix,
+		Padding:   list[0].Padding,
+		Extension: list[0].Extension,
+	}
+	if list[0].Number < 0 {
+		seq.Padding = -1
+		return seq
+	}
+	seq.Frames = make([]int, 0, len(list))
+	for _, item := range list {
+		if item.Padding != seq.Padding {
+			seq.Padding = 1
+		}
+		seq.Frames = append(seq.Frames, item.Number)
+	}
+	return seq
+}
+
+//-------------------------
+// Sequence Methods
+//-------------------------
+
+// Single returns true if the Sequence represents a lone, un-numbered file.
+func (s *Sequence) Single() bool {
+	return s.Padding < 0
+}
+
+// Len returns the number of files the Sequence stands for.
+func (s *Sequence) Len() int {
+	if s.Single() {
+		return 1
+	}
+	return len(s.Frames)
+}
+
+// First returns the lowest frame of the Sequence, or -1 for single files.
+func (s *Sequence) First() int {
+	if len(s.Frames) == 0 {
+		return -1
+	}
+	return s.Frames[0]
+}
+
+// Last returns the highest frame of the Sequence, or -1 for single files.
+func (s *Sequence) Last() int {
+	if len(s.Frames) == 0 {
+		return -1
+	}
+	return s.Frames[len(s.Frames)-1]
+}
+
+// Pattern returns the printf style pattern of the Sequence (eg foo.%04d.exr), or the
+// name of the file for single files.
+func (s *Sequence) Pattern() string {
+	return BuildRangeStringPrefix(&DirItem{s.Prefix, s.First(), s.Padding, s.Extension})
+}
+
+// Ranges returns the frames of the Sequence in condensed range form (eg 1-3,5).
+func (s *Sequence) Ranges() string {
+	return FrameRangeString(s.Frames)
+}
+
+// Name returns the name of the member of the Sequence with the supplied frame number.
+func (s *Sequence) Name(frame int) string {
+	if s.Single() {
+		return s.Prefix
+	}
+	di := DirItem{s.Prefix, frame, s.Padding, s.Extension}
+	return di.String()
+}
+
+// Names returns the names of all of the members of the Sequence, in frame order.
+func (s *Sequence) Names() []string {
+	if s.Single() {
+		return []string{s.Prefix}
+	}
+	names := make([]string, 0, len(s.Frames))
+	for _, frame := range s.Frames {
+		names = append(names, s.Name(frame))
+	}
+	return names
+}
+
+// Missing returns the frames between First and Last which are absent from the Sequence.
+func (s *Sequence) Missing() []int {
+	missing := []int{}
+	for i := 1; i < len(s.Frames); i++ {
+		for f := s.Frames[i-1] + 1; f < s.Frames[i]; f++ {
+			missing = append(missing, f)
+		}
+	}
+	return missing
+}
+
+// HasGaps returns true if the Sequence is missing any frames between First and Last.
+func (s *Sequence) HasGaps() bool {
+	return len(s.Frames) > 0 && s.Last()-s.First()+1 != len(s.Frames)
+}
+
+// ApproxLen returns the approximate length of the Sequence's pattern, as DirItem.ApproxLen.
+// Use DisplayWidth(s.Pattern()) where the exact width is needed.
+func (s *Sequence) ApproxLen() int {
+	di := DirItem{s.Prefix, s.First(), s.Padding, s.Extension}
+	return di.ApproxLen()
+}
+
+// RangeString returns the Sequence in the same form as BuildRangeString, ie the count, the
+// pattern padded to rangePadding, and the frames in condensed range form:
+// 3     foo.%04d.mb    1-2,4
+// Single files, and sequences of a single frame, are shown by name.
+func (s *Sequence) RangeString(rangePadding int) string {
+	padding := 5 // matches BuildRangeString
+	if s.Len() == 1 {
+		return PadInt(1, padding) + " " + s.Names()[0]
+	}
+	return PadInt(s.Len(), padding) + " " + PadToSize(s.Pattern(), rangePadding, false) +
+		"    " + s.Ranges()
+}
+
+// Items expands the Sequence back into a DirItemList.
+func (s *Sequence) Items() DirItemList {
+	if s.Single() {
+		return DirItemList{*NewDirItem(s.Prefix)}
+	}
+	items := make(DirItemList, 0, len(s.Frames))
+	for _, frame := range s.Frames {
+		padding := s.Padding
+		if padding <= 1 {
+			padding = len(strconv.Itoa(frame))
+		}
+		items = append(items, *NewDirRangeItem(s.Prefix, frame, padding, s.Extension))
+	}
+	return items
+}
+
+// String returns the Sequence in the form "pattern ranges" (eg foo.%04d.exr 1-3,5).
+func (s *Sequence) String() string {
+	if s.Single() {
+		return s.Prefix
+	}
+	return s.Pattern() + " " + s.Ranges()
+}
+
+//-------------------------------
+// Sequence Functions
+//-------------------------------
+
+// SequencesFromStringSlice collapses a slice of directory entry names into Sequences using a
+// SequenceBuilder. The Sequences are returned ordered by prefix, extension, padding and first
+// frame.
+//
+// Names which the DirItem parser cannot reproduce exactly (eg foo.12abc) are kept as single
+// files, so that Sequence.Names always yields the original entries.
+func SequencesFromStringSlice(contents []string) []Sequence {
+	builder := NewSequenceBuilder()
+	for _, name := range contents {
+		builder.Add(name)
+	}
+	return builder.Sequences()
+}
+
+// RangeStringsFromSequences formats each of the supplied Sequences as RangeString does,
+// aligning the columns by their rendered widths (see Formatter.Lines). The returned slice is
+// parallel to seqs.
+func RangeStringsFromSequences(seqs []Sequence) []string {
+	return (&Formatter{}).Lines(seqs)
+}
+
+// sequenceLess orders Sequences naturally by prefix, then by extension, padding and
+// first frame.
+func sequenceLess(lhs *Sequence, rhs *Sequence) bool {
+	switch {
+	case lhs.Prefix != rhs.Prefix:
+		return handysort.StringLess(lhs.Prefix, rhs.Prefix)
+	case lhs.Extension != rhs.Extension:
+		return strings.Compare(lhs.Extension, rhs.Extension) < 0
+	case lhs.Padding != rhs.Padding:
+		return lhs.Padding < rhs.Padding
+	default:
+		return lhs.First() < rhs.First()
+	}
+}
@@ -0,0 +1,79 @@
+package lss
+
+import (
+	"testing"
+)
+
+func TestSequence_FromStringSlice(t *testing.T) {
+	contents := []string{
+		"foo.0003.exr",
+		"foo.0001.exr",
+		"foo.0002.exr",
+		"foo.0005.exr",
+		"bar.1.exr",
+		"bar.10.exr",
+		"bar.9.exr",
+		"readme.txt",
+	}
+	expected := []string{
+		"bar.%d.exr 1,9-10",
+		"foo.%04d.exr 1-3,5",
+		"readme.txt",
+	}
+
+	seqs := SequencesFromStringSlice(contents)
+	if len(seqs) != len(expected) {
+		t.Fatal("Wrong number of sequences:", len(seqs), "Should Be:", len(expected), seqs)
+	}
+	for i, seq := range seqs {
+		if seq.String() != expected[i] {
+			t.Error("sequence", i, "is", seq.String(), "Should Be:", expected[i])
+		}
+	}
+}
+
+func TestSequence_NamesRoundTrip(t *testing.T) {
+	contents := []string{
+		"foo.0001.exr",
+		"foo.0002.exr",
+		"foo.9.exr",
+		"foo.10.exr",
+		"foo.12abc",
+		"foo",
+	}
+	names := []string{}
+	for _, seq := range SequencesFromStringSlice(contents) {
+		names = append(names, seq.Names()...)
+	}
+	if len(names) != len(contents) {
+		t.Fatal("Wrong number of names:", names)
+	}
+	for _, name := range contents {
+		if !Contains(names, name) {
+			t.Error("Sequence names do not contain", name, names)
+		}
+	}
+}
+
+func TestSequence_Gaps(t *testing.T) {
+	seq := Sequence{Prefix: "foo", Padding: 4, Extension: ".exr", Frames: []int{1, 2, 5, 7}}
+	if !seq.HasGaps() {
+		t.Error("HasGaps did not detect missing frames")
+	}
+	if missing := FrameRangeString(seq.Missing()); missing != "3-4,6" {
+		t.Error("Missing returned", missing, "Should Be: 3-4,6")
+	}
+	if seq.First() != 1 || seq.Last() != 7 || seq.Len() != 4 {
+		t.Error("Wrong First, Last or Len:", seq.First(), seq.Last(), seq.Len())
+	}
+}
+
+func TestSequence_Single(t *testing.T) {
+	seq := Sequence{Prefix: "readme.txt", Padding: -1}
+	if !seq.Single() || seq.Len() != 1 || seq.HasGaps() {
+		t.Error("Single file Sequence misbehaves:", seq)
+	}
+	if seq.Pattern() != "readme.txt" {
+		t.Error("Single file Sequence pattern is", seq.Pattern())
+	}
+}
@@ -0,0 +1,235 @@
+package lss
+
+/*
+shots provides the checking of sequences against the frame ranges of a pipeline's shots. Each
+Shot has a cut in and cut out, and a number of handle frames beyond each, and the sequences of
+a shot are expected to hold every frame from cut in less the handles to cut out plus the
+handles, and no others.
+
+A sequence belongs to the shot whose Prefix its own prefix starts with, the longest such Prefix
+winning, so that shots sh010 and sh0100 may be told apart. Shot lists are read from JSON, or
+from a simple subset of YAML, mapping each shot's name to its ranges:
+
+	{"sh010": {"cut_in": 1001, "cut_out": 1048, "handles": 8}}
+
+	# shots.yaml
+	sh010:
+	  cut_in: 1001
+	  cut_out: 1048
+	  handles: 8
+	  prefix: sh010_
+*/
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//-------------------------
+// Type Shot
+//-------------------------
+
+// Shot records the frame range of a shot, and the prefix rule matching its sequences.
+type Shot struct {
+	Name    string
+	Prefix  string // sequence prefixes starting with this belong to the shot; the Name by default
+	CutIn   int
+	CutOut  int
+	Handles int // frames beyond the cut at each end
+}
+
+// First returns the first frame expected of the shot's sequences: its cut in, less handles.
+func (s *Shot) First() int {
+	return s.CutIn - s.Handles
+}
+
+// Last returns the last frame expected of the shot's sequences: its cut out, plus handles.
+func (s *Shot) Last() int {
+	return s.CutOut + s.Handles
+}
+
+// Check compares the frames of seq with the range of the shot, returning the frames of the
+// range which seq lacks, and the frames of seq outside the range, in order.
+func (s *Shot) Check(seq *Sequence) (missing []int, extra []int) {
+	missing, extra = []int{}, []int{}
+	present := map[int]bool{}
+	for _, frame := range seq.Frames {
+		present[frame] = true
+		if frame < s.First() || frame > s.Last() {
+			extra = append(extra, frame)
+		}
+	}
+	for frame := s.First(); frame <= s.Last(); frame++ {
+		if !present[frame] {
+			missing = append(missing, frame)
+		}
+	}
+	sort.Ints(extra)
+	return missing, extra
+}
+
+//-------------------------
+// Type Shots
+//-------------------------
+
+// Shots is a list of shots, ordered by name.
+type Shots []Shot
+
+// Match returns the shot to which a sequence with the given prefix belongs, or nil if none.
+func (s Shots) Match(prefix string) *Shot {
+	var match *Shot
+	for i := range s {
+		if strings.HasPrefix(prefix, s[i].Prefix) && (match == nil || len(s[i].Prefix) > len(match.Prefix)) {
+			match = &s[i]
+		}
+	}
+	return match
+}
+
+//-------------------------
+// Shot Functions
+//-------------------------
+
+// ReadShots reads a list of shots, as JSON or as YAML, from r. name is used to identify r in
+// errors.
+func ReadShots(name string, r io.Reader) (error, Shots) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err, nil
+	}
+
+	byName := map[string]*shotFields{}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		decoder := json.NewDecoder(bytes.NewReader(trimmed))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&byName); err != nil {
+			return errors.New(name + ": " + err.Error()), nil
+		}
+	} else if err, byName = parseShotsYAML(name, data); err != nil {
+		return err, nil
+	}
+
+	shots := Shots{}
+	for shotName, fields := range byName {
+		switch {
+		case fields == nil:
+			return errors.New(name + ": shot " + shotName + " has no ranges"), nil
+		case fields.CutIn == nil:
+			return errors.New(name + ": shot " + shotName + " has no cut_in"), nil
+		case fields.CutOut == nil:
+			return errors.New(name + ": shot " + shotName + " has no cut_out"), nil
+		}
+		shot := Shot{Name: shotName, Prefix: fields.Prefix, CutIn: *fields.CutIn, CutOut: *fields.CutOut, Handles: fields.Handles}
+		if shot.Prefix == "" {
+			shot.Prefix = shotName
+		}
+		switch {
+		case shot.CutOut < shot.CutIn:
+			return errors.New(name + ": shot " + shotName + " cuts out before it cuts in"), nil
+		case shot.Handles < 0:
+			return errors.New(name + ": shot " + shotName + " has negative handles"), nil
+		}
+		shots = append(shots, shot)
+	}
+	sort.Slice(shots, func(i, j int) bool { return shots[i].Name < shots[j].Name })
+	return nil, shots
+}
+
+// LoadShots reads the list of shots in the file at path.
+func LoadShots(path string) (error, Shots) {
+	f, err := os.Open(path)
+	if err != nil {
+		return err, nil
+	}
+	defer f.Close()
+	return ReadShots(path, f)
+}
+
+//-----------------------------------------
+// Private Utility Types & Functions
+//-----------------------------------------
+
+// shotFields holds the fields of a shot as read, so that those which are required may be
+// told apart from those set to 0.
+type shotFields struct {
+	Prefix  string `json:"prefix"`
+	CutIn   *int   `json:"cut_in"`
+	CutOut  *int   `json:"cut_out"`
+	Handles int    `json:"handles"`
+}
+
+// parseShotsYAML reads the subset of YAML which ReadShots accepts: a mapping of shot names,
+// each to an indented mapping of its fields, with # comments.
+func parseShotsYAML(name string, data []byte) (error, map[string]*shotFields) {
+	shots := map[string]*shotFields{}
+	var shot *shotFields
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		where := name + ":" + strconv.Itoa(lineNo) + ": "
+		line := scanner.Text()
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed[0] == '#' || trimmed == "---" {
+			continue
+		}
+		key, value, ok := strings.Cut(trimmed, ":")
+		key, value = unquoteYAML(strings.TrimSpace(key)), unquoteYAML(strings.TrimSpace(value))
+		if !ok || key == "" {
+			return errors.New(where + "expected key: value"), nil
+		}
+
+		if line[0] != ' ' && line[0] != '\t' {
+			if value != "" {
+				return errors.New(where + "expected the fields of shot " + key + " on the lines below it"), nil
+			}
+			if shots[key] != nil {
+				return errors.New(where + "shot " + key + " is given twice"), nil
+			}
+			shot = &shotFields{}
+			shots[key] = shot
+			continue
+		}
+		if shot == nil {
+			return errors.New(where + "'" + key + "' is outside of any shot"), nil
+		}
+		if key == "prefix" {
+			shot.Prefix = value
+			continue
+		}
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.New(where + key + " must be a frame number, not '" + value + "'"), nil
+		}
+		switch key {
+		case "cut_in":
+			shot.CutIn = &number
+		case "cut_out":
+			shot.CutOut = &number
+		case "handles":
+			shot.Handles = number
+		default:
+			return errors.New(where + "unknown field '" + key + "' (expected cut_in, cut_out, handles or prefix)"), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err, nil
+	}
+	return nil, shots
+}
+
+// unquoteYAML removes the single or double quotes around a YAML scalar, if any.
+func unquoteYAML(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,113 @@
+package lss
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShots_ReadShots(t *testing.T) {
+	expected := Shots{
+		{Name: "sh010", Prefix: "sh010", CutIn: 1001, CutOut: 1048, Handles: 8},
+		{Name: "sh020", Prefix: "sh020_", CutIn: 1001, CutOut: 1120},
+	}
+	inputs := map[string]string{
+		"json": `{"sh020": {"cut_in": 1001, "cut_out": 1120, "prefix": "sh020_"},
+			"sh010": {"cut_in": 1001, "cut_out": 1048, "handles": 8}}`,
+		"yaml": `# shots
+sh020:
+  cut_in: 1001
+  cut_out: 1120   # no handles
+  prefix: "sh020_"
+sh010:
+  cut_in: 1001
+  cut_out: 1048
+  handles: 8
+`,
+	}
+	for format, input := range inputs {
+		err, shots := ReadShots(format, strings.NewReader(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(shots) != len(expected) {
+			t.Fatalf("%s: read %v Should Be: %v", format, shots, expected)
+		}
+		for i := range shots {
+			if shots[i] != expected[i] {
+				t.Errorf("%s: read %v Should Be: %v", format, shots[i], expected[i])
+			}
+		}
+	}
+
+	bad := []string{
+		`{"sh010": {"cut_in": 1001, "cut_out": 1048, "handle": 8}}`,
+		`{"sh010": {"cut_in": 1048, "cut_out": 1001}}`,
+		"sh010: 1001\n",
+		"  cut_in: 1001\n",
+		"sh010:\n  cut_in: first\n",
+		"sh010:\n  cutin: 1001\n",
+		"sh010:\n  cut_in: 1\n  cut_out: 2\n  handles: -1\n",
+		`{"sh010": {"cut_out": 1010}}`,
+		`{"sh010": {"cut_in": 1001}}`,
+		"sh010:\n  cut_out: 1010\n",
+		"sh010:\n  cut_in: 1001\n  handles: 8\n",
+		"sh010:\n  cut_in: 1\n  cut_out: 2\nsh010:\n  cut_in: 1\n  cut_out: 2\n",
+	}
+	for _, input := range bad {
+		if err, _ := ReadShots("bad", strings.NewReader(input)); err == nil {
+			t.Errorf("ReadShots(%q) did not fail", input)
+		}
+	}
+}
+
+func TestShots_Match(t *testing.T) {
+	shots := Shots{{Name: "sh010", Prefix: "sh010"}, {Name: "sh0100", Prefix: "sh0100"}}
+	tests := map[string]string{
+		"sh010_bg":   "sh010",
+		"sh0100_bg":  "sh0100",
+		"sh020_bg":   "",
+		"bg_sh010":   "",
+		"sh010":      "sh010",
+		"sh01000_fg": "sh0100",
+	}
+	for prefix, expected := range tests {
+		name := ""
+		if shot := shots.Match(prefix); shot != nil {
+			name = shot.Name
+		}
+		if name != expected {
+			t.Errorf("Match(%s) returned %q Should Be: %q", prefix, name, expected)
+		}
+	}
+}
+
+func TestShots_Check(t *testing.T) {
+	shot := Shot{Name: "sh010", CutIn: 1001, CutOut: 1010, Handles: 2}
+	tests := []struct {
+		names   []string
+		missing string
+		extra   string
+	}{
+		{[]string{"sh010.0999.exr", "sh010.1012.exr"}, "1000-1011", ""},
+		{[]string{"sh010.0995.exr", "sh010.0996.exr"}, "999-1012", "995-996"},
+	}
+	for _, test := range tests {
+		seq := SequencesFromStringSlice(test.names)[0]
+		missing, extra := shot.Check(&seq)
+		if FrameRangeString(missing) != test.missing || FrameRangeString(extra) != test.extra {
+			t.Errorf("Check(%s) returned %v, %v Should Be: %s, %s", seq.String(), missing, extra, test.missing, test.extra)
+		}
+	}
+
+	frames := []int{}
+	for frame := 999; frame <= 1013; frame++ {
+		if frame != 1005 {
+			frames = append(frames, frame)
+		}
+	}
+	seq := Sequence{Prefix: "sh010", Padding: 4, Extension: ".exr", Frames: frames}
+	missing, extra := shot.Check(&seq)
+	if FrameRangeString(missing) != "1005" || FrameRangeString(extra) != "1013" {
+		t.Errorf("Check returned %v, %v Should Be: 1005, 1013", missing, extra)
+	}
+}
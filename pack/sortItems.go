@@ -6,6 +6,7 @@ StringChunks - a []string representing chunks of a string split into number and
 StringChunkList
 
 */
+import "context"
 import "fmt"
 import "strings"
 import "sort"
@@ -128,14 +129,25 @@ func NewStringChunksList(inStrings []string) StringChunksList {
 // in the inStrings slice, and sends them to a channel via an anonymous
 // go routine, before closing the channel.
 //
-// The function returns a StringChunks chan.
+// The function returns a StringChunks chan, which must be drained. Use
+// ChunkStringsToChanCtx to stop early.
 func ChunkStringsToChan(inStrings []string) chan StringChunks {
+	return ChunkStringsToChanCtx(context.Background(), inStrings)
+}
+
+// ChunkStringsToChanCtx behaves as ChunkStringsToChan, but stops sending
+// and closes the channel once ctx is cancelled.
+func ChunkStringsToChanCtx(ctx context.Context, inStrings []string) chan StringChunks {
 	ch := make(chan StringChunks)
 	go func() {
+		defer close(ch)
 		for _, val := range inStrings {
-			ch <- GenChunks(val)
+			select {
+			case ch <- GenChunks(val):
+			case <-ctx.Done():
+				return
+			}
 		}
-		close(ch)
 	}()
 	return ch
 }
@@ -154,14 +166,25 @@ func (s StringChunksList) SliceOfStrings() []string {
 }
 
 // StringChan method returns a channel which we broadcast all
-// of the stringchunks too
+// of the stringchunks too. The channel must be drained; use
+// StringChanCtx to stop early.
 func (s StringChunksList) StringChan() chan string {
+	return s.StringChanCtx(context.Background())
+}
+
+// StringChanCtx behaves as StringChan, but stops broadcasting and
+// closes the channel once ctx is cancelled.
+func (s StringChunksList) StringChanCtx(ctx context.Context) chan string {
 	ch := make(chan string)
 	go func() {
+		defer close(ch)
 		for _, value := range s {
-			ch <- value.String()
+			select {
+			case ch <- value.String():
+			case <-ctx.Done():
+				return
+			}
 		}
-		close(ch)
 	}()
 	return ch
 }
@@ -268,10 +291,9 @@ func (s StringChunksList) Sort() {
 // NaturalSort
 //     Given a list of strings, return a sorted list of strings.
 func NaturalSort(items []string) []string {
-	itemlist := NewStringChunksList(items)
-	sort.Sort(itemlist)
-	return itemlist.SliceOfStrings()
-
+	sorted := append([]string{}, items...)
+	Stringlist(sorted).NaturalSort()
+	return sorted
 }
 
 //------------------------------------
@@ -279,12 +301,12 @@ func NaturalSort(items []string) []string {
 //------------------------------------
 type Stringlist []string
 
+// NaturalSort
+//     Sorts the Stringlist in place, using NaturalLess.
 func (s Stringlist) NaturalSort() {
-	itemlist := NewStringChunksList([]string(s))
-	sort.Sort(itemlist)
-	for i, val := range itemlist {
-		s[i] = val.String()
-	}
+	sort.Slice(s, func(i, j int) bool {
+		return NaturalLess(s[i], s[j])
+	})
 }
 
 func (s Stringlist) String() string {
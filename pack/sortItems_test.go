@@ -1,6 +1,9 @@
 package lss
 
 import (
+	"context"
+	"runtime"
+	"strconv"
 	"strings"
 	"testing"
 )
@@ -121,3 +124,21 @@ for x in range sortDirItems(genDirItems(dirItemStrings) {
 }
 
 */
+
+func TestSortItems_StreamsStopOnCancel(t *testing.T) {
+	strs := []string{}
+	for i := 0; i < 100; i++ {
+		strs = append(strs, "this_is_v01."+strconv.Itoa(i)+".exr")
+	}
+	baseline := runtime.NumGoroutine()
+
+	for i := 0; i < 10; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		chunks := ChunkStringsToChanCtx(ctx, strs)
+		<-chunks
+		names := NewStringChunksList(strs).StringChanCtx(ctx)
+		<-names
+		cancel()
+	}
+	waitForGoroutines(t, baseline)
+}
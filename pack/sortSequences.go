@@ -0,0 +1,148 @@
+package lss
+
+/*
+sortSequences provides the ordering options for collapsed listings. Unlike the natural sort
+in sortItems.go, which orders individual names, these orderings apply to whole Sequences, so
+that the newest render or the biggest cache in a directory may be found at a glance.
+*/
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+//-------------------------
+// Type SortKey
+//-------------------------
+
+type SortKey int
+
+const (
+	SORT_NAME  SortKey = iota // natural order of the pattern
+	SORT_SIZE                 // total size of the members, largest first
+	SORT_TIME                 // newest member modification time, newest first
+	SORT_COUNT                // number of members, most first
+	SORT_START                // first frame, lowest first
+	SORT_END                  // last frame, lowest first
+)
+
+// sortKeyNames maps the names accepted by ParseSortKey to SortKeys.
+var sortKeyNames = map[string]SortKey{
+	"name":  SORT_NAME,
+	"size":  SORT_SIZE,
+	"time":  SORT_TIME,
+	"count": SORT_COUNT,
+	"start": SORT_START,
+	"end":   SORT_END,
+}
+
+// ParseSortKey converts one of name, size, time, count, start or end into a SortKey.
+func ParseSortKey(name string) (error, SortKey) {
+	key, ok := sortKeyNames[name]
+	if !ok {
+		return errors.New("unknown sort key '" + name + "' (use name, size, time, count, start or end)"), SORT_NAME
+	}
+	return nil, key
+}
+
+// NeedsStat returns true if sorting by the key requires the files to be stat-ed.
+func (k SortKey) NeedsStat() bool {
+	return k == SORT_SIZE || k == SORT_TIME
+}
+
+//-------------------------
+// Type SequenceStat
+//-------------------------
+
+// SequenceStat summarizes the members of a Sequence on disk.
+type SequenceStat struct {
+	Size    int64     // total size of the members, in bytes
+	ModTime time.Time // modification time of the most recently modified member
+}
+
+// StatSequence stats each member of the Sequence within dir. Members which have vanished
+// since the directory was listed are skipped; other errors are returned.
+func StatSequence(dir string, seq *Sequence) (error, SequenceStat) {
+	stat := SequenceStat{}
+	for _, name := range seq.Names() {
+		info, err := os.Lstat(filepath.Join(dir, name))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err, stat
+		}
+		stat.Size += info.Size()
+		if info.ModTime().After(stat.ModTime) {
+			stat.ModTime = info.ModTime()
+		}
+	}
+	return nil, stat
+}
+
+//-------------------------
+// Sorting Functions
+//-------------------------
+
+// SortSequences sorts seqs, found within dir, in place by the supplied key. Ties are broken
+// using the natural order of the patterns. If reverse is true the order is flipped.
+func SortSequences(dir string, seqs []Sequence, key SortKey, reverse bool) error {
+	stats := make([]SequenceStat, len(seqs))
+	if key.NeedsStat() {
+		for i := range seqs {
+			err, stat := StatSequence(dir, &seqs[i])
+			if err != nil {
+				return err
+			}
+			stats[i] = stat
+		}
+	}
+
+	// sort an index, so that stats stay aligned with their sequences
+	idx := make([]int, len(seqs))
+	for i := range idx {
+		idx[i] = i
+	}
+	less := func(i, j int) bool {
+		lhs, rhs := &seqs[i], &seqs[j]
+		switch key {
+		case SORT_SIZE:
+			if stats[i].Size != stats[j].Size {
+				return stats[i].Size > stats[j].Size
+			}
+		case SORT_TIME:
+			if !stats[i].ModTime.Equal(stats[j].ModTime) {
+				return stats[i].ModTime.After(stats[j].ModTime)
+			}
+		case SORT_COUNT:
+			if lhs.Len() != rhs.Len() {
+				return lhs.Len() > rhs.Len()
+			}
+		case SORT_START:
+			if lhs.First() != rhs.First() {
+				return lhs.First() < rhs.First()
+			}
+		case SORT_END:
+			if lhs.Last() != rhs.Last() {
+				return lhs.Last() < rhs.Last()
+			}
+		}
+		return sequenceLess(lhs, rhs)
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		if reverse {
+			return less(idx[b], idx[a])
+		}
+		return less(idx[a], idx[b])
+	})
+
+	sorted := make([]Sequence, len(seqs))
+	for i, j := range idx {
+		sorted[i] = seqs[j]
+	}
+	copy(seqs, sorted)
+	return nil
+}
@@ -0,0 +1,75 @@
+package lss
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func sequencePatterns(seqs []Sequence) []string {
+	patterns := []string{}
+	for _, seq := range seqs {
+		patterns = append(patterns, seq.Pattern())
+	}
+	return patterns
+}
+
+func TestSortSequences_Keys(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	files := map[string]int{
+		"big.0001.exr":   1000,
+		"small.0010.exr": 1,
+		"small.0011.exr": 1,
+		"small.0012.exr": 1,
+		"mid.0005.exr":   10,
+		"mid.0100.exr":   10,
+	}
+	for name, size := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+		os.Chtimes(path, old, old)
+	}
+	newest := filepath.Join(dir, "mid.0100.exr")
+	os.Chtimes(newest, old.Add(time.Hour), old.Add(time.Hour))
+
+	names := []string{}
+	for name := range files {
+		names = append(names, name)
+	}
+
+	tests := []struct {
+		key      string
+		reverse  bool
+		expected []string
+	}{
+		{"name", false, []string{"big.%04d.exr", "mid.%04d.exr", "small.%04d.exr"}},
+		{"name", true, []string{"small.%04d.exr", "mid.%04d.exr", "big.%04d.exr"}},
+		{"size", false, []string{"big.%04d.exr", "mid.%04d.exr", "small.%04d.exr"}},
+		{"time", false, []string{"mid.%04d.exr", "big.%04d.exr", "small.%04d.exr"}},
+		{"count", false, []string{"small.%04d.exr", "mid.%04d.exr", "big.%04d.exr"}},
+		{"start", false, []string{"big.%04d.exr", "mid.%04d.exr", "small.%04d.exr"}},
+		{"end", false, []string{"big.%04d.exr", "small.%04d.exr", "mid.%04d.exr"}},
+		{"end", true, []string{"mid.%04d.exr", "small.%04d.exr", "big.%04d.exr"}},
+	}
+	for _, test := range tests {
+		err, key := ParseSortKey(test.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		seqs := SequencesFromStringSlice(names)
+		if err := SortSequences(dir, seqs, key, test.reverse); err != nil {
+			t.Fatal(err)
+		}
+		if patterns := sequencePatterns(seqs); !testEq(patterns, test.expected) {
+			t.Error(test.key, test.reverse, "sorted", patterns, "Should Be:", test.expected)
+		}
+	}
+
+	if err, _ := ParseSortKey("colour"); err == nil {
+		t.Error("ParseSortKey accepted an unknown key")
+	}
+}
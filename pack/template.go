@@ -0,0 +1,207 @@
+package lss
+
+/*
+template renders Sequences through user supplied text/template templates, so that a listing may
+take whatever shape the tools downstream want, eg
+
+    {{.Pattern}} {{.First}}-{{.Last}} ({{.Count}})
+
+Each Sequence is presented to the template as a SequenceData (see its fields and methods for
+the data model). The sizes and times of the members are only read from disk if the template
+asks for them.
+
+Besides the builtin functions, templates may call
+
+human  - formats a number of bytes for people (eg {{human .Size}} => 1.2G)
+pad    - pads a value with spaces to a width, on the right, or on the left if the width is
+         negative (eg {{pad 20 .Pattern}})
+*/
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+//-------------------------
+// Type SequenceData
+//-------------------------
+
+// SequenceData is the data model of a Sequence within an output template.
+type SequenceData struct {
+	Dir     string // directory holding the sequence
+	Prefix  string // name up to the frame number (eg foo for foo.0001.exr)
+	Padding int    // width of the frame numbers, 1 if unpadded, or -1 for a single file
+	Ext     string // extension, including the leading period (eg .exr), or ""
+	Pattern string // the pattern (eg foo.%04d.exr) in the chosen notation, or the file's name
+	Name    string // name of the first member
+	Frames  []int  // the frame numbers, in order
+	First   int    // the first frame, or -1 for a single file
+	Last    int    // the last frame, or -1 for a single file
+	Count   int    // the number of members
+	Ranges  string // the frames in condensed range form (eg 1-3,5)
+	Missing string // the frames missing between First and Last, in range form
+	Gaps    bool   // whether any frames are missing
+	Single  bool   // whether this is a single file rather than a sequence
+
+	seq  *Sequence
+	stat *SequenceStat
+}
+
+// NewSequenceData returns the SequenceData of seq, found within dir, with its pattern and
+// frames written in style. If dir is "", the directory is taken from the Sequence's prefix.
+func NewSequenceData(dir string, seq *Sequence, style Style) *SequenceData {
+	if dir == "" {
+		dir = "."
+		if i := strings.LastIndexByte(seq.Prefix, filepath.Separator); i >= 0 {
+			dir = seq.Prefix[:i+1]
+			trimmed := *seq
+			trimmed.Prefix = seq.Prefix[i+1:]
+			seq = &trimmed
+		}
+	}
+	return &SequenceData{
+		Dir:     filepath.Clean(dir),
+		Prefix:  seq.Prefix,
+		Padding: seq.Padding,
+		Ext:     seq.Extension,
+		Pattern: style.Pattern(seq),
+		Name:    seq.Names()[0],
+		Frames:  seq.Frames,
+		First:   seq.First(),
+		Last:    seq.Last(),
+		Count:   seq.Len(),
+		Ranges:  style.Ranges(seq.Frames),
+		Missing: style.Ranges(seq.Missing()),
+		Gaps:    seq.HasGaps(),
+		Single:  seq.Single(),
+		seq:     seq,
+	}
+}
+
+// Path returns the path of the Sequence's pattern (eg renders/foo.%04d.exr).
+func (d *SequenceData) Path() string {
+	return filepath.Join(d.Dir, d.Pattern)
+}
+
+// Size returns the total size of the members on disk, in bytes.
+func (d *SequenceData) Size() (int64, error) {
+	err := d.statMembers()
+	if err != nil {
+		return 0, err
+	}
+	return d.stat.Size, nil
+}
+
+// ModTime returns the modification time of the most recently modified member.
+func (d *SequenceData) ModTime() (time.Time, error) {
+	err := d.statMembers()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return d.stat.ModTime, nil
+}
+
+// Sizes returns the size of each member on disk, in bytes, in frame order. Members which have
+// vanished have a size of -1.
+func (d *SequenceData) Sizes() ([]int64, error) {
+	names := d.seq.Names()
+	sizes := make([]int64, len(names))
+	for i, name := range names {
+		info, err := os.Lstat(filepath.Join(d.Dir, name))
+		switch {
+		case os.IsNotExist(err):
+			sizes[i] = -1
+		case err != nil:
+			return nil, err
+		default:
+			sizes[i] = info.Size()
+		}
+	}
+	return sizes, nil
+}
+
+// statMembers stats the members, once.
+func (d *SequenceData) statMembers() error {
+	if d.stat != nil {
+		return nil
+	}
+	err, stat := StatSequence(d.Dir, d.seq)
+	if err != nil {
+		return err
+	}
+	d.stat = &stat
+	return nil
+}
+
+//-------------------------
+// Type Template
+//-------------------------
+
+// Template is a parsed output template.
+type Template struct {
+	tmpl *template.Template
+}
+
+// ParseTemplate parses text as an output template.
+func ParseTemplate(text string) (error, *Template) {
+	tmpl, err := template.New("output").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return err, nil
+	}
+	return nil, &Template{tmpl: tmpl}
+}
+
+// Execute renders data to w, adding a newline unless the template supplies one.
+func (t *Template) Execute(w io.Writer, data *SequenceData) error {
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+	if buf.Len() == 0 || buf.Bytes()[buf.Len()-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+//-----------------------------------------
+// Private Utility Types & Functions
+//-----------------------------------------
+
+var templateFuncs = template.FuncMap{
+	"human": humanSize,
+	"pad":   padValue,
+}
+
+// humanSize formats a number of bytes using binary multiples (eg 1.2G).
+func humanSize(size int64) string {
+	const units = "KMGTPE"
+	if size < 1024 {
+		return strconv.FormatInt(size, 10)
+	}
+	value, unit := float64(size), -1
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	if value < 10 {
+		return strconv.FormatFloat(value, 'f', 1, 64) + units[unit:unit+1]
+	}
+	return strconv.FormatFloat(value, 'f', 0, 64) + units[unit:unit+1]
+}
+
+// padValue formats value, padded with spaces to width cells, on the left if width is negative.
+func padValue(width int, value interface{}) string {
+	s := fmt.Sprint(value)
+	if width < 0 {
+		return PadToSize(s, -width, true)
+	}
+	return PadToSize(s, width, false)
+}
@@ -0,0 +1,74 @@
+package lss
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTemplate_Execute(t *testing.T) {
+	seqs := SequencesFromStringSlice([]string{"foo.0001.exr", "foo.0002.exr", "foo.0004.exr", "notes.txt"})
+	tests := map[string][]string{
+		"{{.Pattern}} {{.First}}-{{.Last}} ({{.Count}})":    {"foo.%04d.exr 1-4 (3)\n", "notes.txt -1--1 (1)\n"},
+		"{{.Prefix}}|{{.Padding}}|{{.Ext}}|{{.Ranges}}":     {"foo|4|.exr|1-2,4\n", "notes.txt|-1||\n"},
+		"{{.Missing}} {{.Gaps}} {{.Single}} {{.Name}}":      {"3 true false foo.0001.exr\n", " false true notes.txt\n"},
+		"{{range .Frames}}{{.}} {{end}}\n":                  {"1 2 4 \n", "\n"},
+		"{{.Dir}} {{.Path}}":                                {"renders renders/foo.%04d.exr\n", "renders renders/notes.txt\n"},
+		"{{pad 14 .Pattern}}|{{pad -3 .Count}}|{{human 0}}": {"foo.%04d.exr  |  3|0\n", "notes.txt     |  1|0\n"},
+	}
+	for text, expected := range tests {
+		err, tmpl := ParseTemplate(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range seqs {
+			var buf bytes.Buffer
+			if err := tmpl.Execute(&buf, NewSequenceData("renders", &seqs[i], Style{})); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != expected[i] {
+				t.Errorf("%q rendered %q Should Be: %q", text, buf.String(), expected[i])
+			}
+		}
+	}
+}
+
+func TestTemplate_DirFromPrefix(t *testing.T) {
+	seqs := SequencesFromStringSlice([]string{"shot/foo.0001.exr", "shot/foo.0002.exr"})
+	data := NewSequenceData("", &seqs[0], Style{})
+	if data.Dir != "shot" || data.Prefix != "foo" || data.Pattern != "foo.%04d.exr" {
+		t.Error("NewSequenceData split the prefix as", data.Dir, data.Prefix, data.Pattern)
+	}
+	if data = NewSequenceData("", &SequencesFromStringSlice([]string{"notes.txt"})[0], Style{}); data.Dir != "." {
+		t.Error("NewSequenceData of a bare name has Dir", data.Dir)
+	}
+}
+
+func TestTemplate_Sizes(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "foo.0001.exr"), make([]byte, 2048), 0644)
+	os.WriteFile(filepath.Join(dir, "foo.0002.exr"), make([]byte, 1024), 0644)
+	seq := SequencesFromStringSlice([]string{"foo.0001.exr", "foo.0002.exr", "foo.0003.exr"})[0]
+
+	err, tmpl := ParseTemplate("{{.Size}} {{human .Size}} {{.Sizes}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, NewSequenceData(dir, &seq, Style{})); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "3072 3.0K [2048 1024 -1]\n"; buf.String() != expected {
+		t.Error("rendered", buf.String(), "Should Be:", expected)
+	}
+}
+
+func TestTemplate_HumanSize(t *testing.T) {
+	tests := map[int64]string{0: "0", 1023: "1023", 1024: "1.0K", 1536: "1.5K", 10 << 20: "10M", 3 << 40: "3.0T"}
+	for size, expected := range tests {
+		if result := humanSize(size); result != expected {
+			t.Error("humanSize(", size, ") =", result, "Should Be:", expected)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package lss
+
+/*
+timecode provides the conversion of frame numbers to and from SMPTE timecode (HH:MM:SS:FF),
+for editorial, who think in durations rather than frames. Frame numbers are counted from
+00:00:00:00, so that at 24 fps frame 1001 is 00:00:41:17.
+
+At the NTSC rates of 29.97 and 59.94 fps, timecode is drop-frame by default: to keep step with
+the clock, the first two (or four, at 59.94) frame numbers of each minute are skipped, except
+every tenth minute. Drop-frame timecode separates the frames with a ";" (HH:MM:SS;FF). Other
+fractional rates (eg 23.976) count their timecode as the nominal whole rate does, as is usual.
+*/
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+//-------------------------
+// Type Rate
+//-------------------------
+
+// Rate is the frame rate at which timecode is counted.
+type Rate struct {
+	FPS       float64 // frames per second, eg 23.976, or 0 if no rate is set
+	Timebase  int     // frames counted per second of timecode, eg 24 for 23.976
+	DropFrame bool    // whether frame numbers are dropped to keep step with the clock
+}
+
+// ParseRate parses a frame rate (eg 24, 23.976 or 29.97). Timecode at 29.97 and 59.94 is
+// drop-frame, unless the rate is followed by ndf (eg 29.97ndf); any rate may be followed by
+// df to ask for drop-frame explicitly, though only those two rates support it. Rates must
+// round to at least 1, and be no more than 1000.
+func ParseRate(s string) (error, Rate) {
+	text, drop := s, -1
+	switch {
+	case strings.HasSuffix(text, "ndf"):
+		text, drop = strings.TrimSuffix(text, "ndf"), 0
+	case strings.HasSuffix(text, "df"):
+		text, drop = strings.TrimSuffix(text, "df"), 1
+	}
+	// the rate must count at least one frame per second of timecode (and NaN is refused too)
+	fps, err := strconv.ParseFloat(text, 64)
+	if err != nil || !(math.Round(fps) >= 1 && fps <= 1000) {
+		return errors.New("bad frame rate '" + s + "' (expected eg 24, 23.976 or 29.97)"), Rate{}
+	}
+	rate := Rate{FPS: fps, Timebase: int(math.Round(fps))}
+	ntsc := (rate.Timebase == 30 || rate.Timebase == 60) && fps != float64(rate.Timebase)
+	switch {
+	case drop == 1 && !ntsc:
+		return errors.New("drop-frame timecode needs a rate of 29.97 or 59.94, not '" + text + "'"), Rate{}
+	case drop == -1:
+		rate.DropFrame = ntsc
+	default:
+		rate.DropFrame = drop == 1
+	}
+	return nil, rate
+}
+
+// String returns the rate as ParseRate accepts it.
+func (r Rate) String() string {
+	s := strconv.FormatFloat(r.FPS, 'f', -1, 64)
+	if (r.Timebase == 30 || r.Timebase == 60) && r.FPS != float64(r.Timebase) && !r.DropFrame {
+		s += "ndf"
+	}
+	return s
+}
+
+// dropped returns the number of frame numbers dropped each minute, or 0 for non drop-frame.
+func (r Rate) dropped() int {
+	if !r.DropFrame {
+		return 0
+	}
+	return r.Timebase / 15
+}
+
+//-------------------------
+// Timecode Functions
+//-------------------------
+
+// FrameToTimecode returns the SMPTE timecode of frame at rate. Timecode wraps at 24 hours,
+// and negative frames count back from it.
+func FrameToTimecode(frame int, rate Rate) string {
+	drop := rate.dropped()
+	perDay := rate.Timebase * 86400
+	if drop > 0 {
+		perDay -= drop * 9 * 6 * 24
+	}
+	frame %= perDay
+	if frame < 0 {
+		frame += perDay
+	}
+
+	if drop > 0 {
+		// restore the dropped frame numbers, so that the timecode may be counted out as usual
+		perMinute := rate.Timebase*60 - drop
+		perTenMinutes := perMinute*10 + drop
+		tens, rest := frame/perTenMinutes, frame%perTenMinutes
+		frame += drop * 9 * tens
+		if rest > drop {
+			frame += drop * ((rest - drop) / perMinute)
+		}
+	}
+
+	sep := ":"
+	if rate.DropFrame {
+		sep = ";"
+	}
+	ff := frame % rate.Timebase
+	seconds := frame / rate.Timebase
+	return fmt.Sprintf("%02d:%02d:%02d%s%02d", seconds/3600, seconds/60%60, seconds%60, sep, ff)
+}
+
+// TimecodeToFrame returns the frame of the SMPTE timecode tc (HH:MM:SS:FF, or HH:MM:SS;FF) at
+// rate. It is the inverse of FrameToTimecode.
+func TimecodeToFrame(tc string, rate Rate) (error, int) {
+	fields := strings.FieldsFunc(tc, func(r rune) bool { return r == ':' || r == ';' || r == '.' })
+	bad := errors.New("bad timecode '" + tc + "' (expected HH:MM:SS:FF)")
+	if len(fields) != 4 {
+		return bad, 0
+	}
+	values := [4]int{}
+	for i, field := range fields {
+		value, err := strconv.Atoi(field)
+		if err != nil || value < 0 {
+			return bad, 0
+		}
+		values[i] = value
+	}
+	hh, mm, ss, ff := values[0], values[1], values[2], values[3]
+	if mm > 59 || ss > 59 || ff >= rate.Timebase {
+		return errors.New("timecode '" + tc + "' is out of range at " + rate.String() + " fps"), 0
+	}
+
+	drop := rate.dropped()
+	minutes := hh*60 + mm
+	if drop > 0 && ss == 0 && ff < drop && mm%10 != 0 {
+		return errors.New("timecode '" + tc + "' is dropped at " + rate.String() + " fps"), 0
+	}
+	return nil, (minutes*60+ss)*rate.Timebase + ff - drop*(minutes-minutes/10)
+}
+
+// Duration returns the time taken to play the given number of frames at rate, in seconds.
+func Duration(frames int, rate Rate) float64 {
+	return float64(frames) / rate.FPS
+}
@@ -0,0 +1,86 @@
+package lss
+
+import (
+	"testing"
+)
+
+func TestTimecode_ParseRate(t *testing.T) {
+	tests := map[string]Rate{
+		"24":       {24, 24, false},
+		"23.976":   {23.976, 24, false},
+		"25":       {25, 25, false},
+		"29.97":    {29.97, 30, true},
+		"29.97ndf": {29.97, 30, false},
+		"29.97df":  {29.97, 30, true},
+		"59.94":    {59.94, 60, true},
+		"30":       {30, 30, false},
+	}
+	for text, expected := range tests {
+		err, rate := ParseRate(text)
+		if err != nil || rate != expected {
+			t.Errorf("ParseRate(%q) returned %v, %v Should Be: %v", text, rate, err, expected)
+		}
+		if _, again := ParseRate(rate.String()); again != rate {
+			t.Errorf("Rate %v did not round trip through %q", rate, rate.String())
+		}
+	}
+	for _, bad := range []string{"", "fast", "0", "-24", "24df", "30df", "0.4", "NaN", "Inf", "1e300"} {
+		if err, _ := ParseRate(bad); err == nil {
+			t.Errorf("ParseRate(%q) did not fail", bad)
+		}
+	}
+}
+
+func TestTimecode_FrameToTimecode(t *testing.T) {
+	tests := []struct {
+		rate     string
+		frame    int
+		expected string
+	}{
+		{"24", 0, "00:00:00:00"},
+		{"24", 1001, "00:00:41:17"},
+		{"24", 86400, "01:00:00:00"},
+		{"24", -1, "23:59:59:23"},
+		{"23.976", 1001, "00:00:41:17"},
+		{"25", 1001, "00:00:40:01"},
+		{"29.97ndf", 1800, "00:01:00:00"},
+		{"29.97", 1799, "00:00:59;29"},
+		{"29.97", 1800, "00:01:00;02"},
+		{"29.97", 17981, "00:09:59;29"},
+		{"29.97", 17982, "00:10:00;00"},
+		{"29.97", 107892, "01:00:00;00"},
+		{"59.94", 3600, "00:01:00;04"},
+	}
+	for _, test := range tests {
+		_, rate := ParseRate(test.rate)
+		if tc := FrameToTimecode(test.frame, rate); tc != test.expected {
+			t.Errorf("FrameToTimecode(%d) at %s returned %s Should Be: %s", test.frame, test.rate, tc, test.expected)
+		}
+		if err, frame := TimecodeToFrame(test.expected, rate); test.frame >= 0 && (err != nil || frame != test.frame) {
+			t.Errorf("TimecodeToFrame(%s) at %s returned %d, %v Should Be: %d", test.expected, test.rate, frame, err, test.frame)
+		}
+	}
+}
+
+func TestTimecode_RoundTrip(t *testing.T) {
+	for _, text := range []string{"24", "25", "29.97", "59.94"} {
+		_, rate := ParseRate(text)
+		for frame := 0; frame < 200000; frame += 7 {
+			if err, back := TimecodeToFrame(FrameToTimecode(frame, rate), rate); err != nil || back != frame {
+				t.Fatalf("frame %d at %s became %s and then %d, %v", frame, text, FrameToTimecode(frame, rate), back, err)
+			}
+		}
+	}
+}
+
+func TestTimecode_TimecodeToFrameErrors(t *testing.T) {
+	_, rate := ParseRate("29.97")
+	for _, bad := range []string{"", "00:00:00", "00:00:00:30", "00:60:00:00", "aa:00:00:00", "00:01:00;00", "00:01:00;01"} {
+		if err, _ := TimecodeToFrame(bad, rate); err == nil {
+			t.Errorf("TimecodeToFrame(%q) did not fail", bad)
+		}
+	}
+	if err, frame := TimecodeToFrame("00:10:00;00", rate); err != nil || frame != 17982 {
+		t.Error("TimecodeToFrame of a tenth minute returned", frame, err)
+	}
+}
@@ -0,0 +1,333 @@
+package lss
+
+/*
+walk provides a parallel recursive listing, for show trees on high latency network storage
+where reading one directory at a time is dominated by round trips. A bounded pool of workers
+reads directories concurrently, each collapsing its directory's entries with the same
+SequenceBuilder used by RangesChanFromStringSlice and CollapsePath, while a single emitter
+publishes the results in a deterministic order: depth first, parents before children, and
+subdirectories in natural order. The output is therefore identical whatever the number of
+workers. Workers read at most WALK_AHEAD directories per job beyond those the emitter has
+published, and published results are released, so that memory is bounded by --jobs rather
+than by the size of the tree.
+
+Errors reading a directory (eg permission denied) are reported with that directory's result
+and do not stop the walk. Symbolic links to directories are listed but not followed.
+*/
+
+import (
+	"container/heap"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// WALK_AHEAD is the number of directories each worker of Walk may read beyond those published.
+const WALK_AHEAD = 4
+
+//-------------------------
+// Type WalkOptions
+//-------------------------
+
+// WalkOptions configures Walk.
+type WalkOptions struct {
+	// Jobs is the number of directories read concurrently. Values below 1 read one at a time.
+	Jobs int
+	// Filter returns the Filter applied to the entries of dir before they are collapsed. A
+	// nil Filter func keeps everything.
+	Filter func(dir string) (error, Filter)
+	// Descend returns the Filter deciding which subdirectories of dir are walked. A nil
+	// Descend func walks every subdirectory.
+	Descend func(dir string) (error, Filter)
+	// Cache, if not nil, is consulted before reading each directory.
+	Cache *Cache
+}
+
+//-------------------------
+// Type WalkResult
+//-------------------------
+
+// WalkResult holds the collapsed contents of a single directory found by Walk. If Err is
+// set, Sequences holds whatever could be read before the error occurred.
+type WalkResult struct {
+	Dir       string
+	Sequences []Sequence
+	Err       error
+}
+
+//-------------------------
+// Walking Functions
+//-------------------------
+
+// Walk lists root and each of its subdirectories, publishing a WalkResult per directory on
+// the returned channel in depth first order. The channel is closed once the walk completes
+// or ctx is cancelled.
+func Walk(ctx context.Context, root string, options WalkOptions) chan WalkResult {
+	jobs := options.Jobs
+	if jobs < 1 {
+		jobs = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	queue := newWalkQueue()
+	rootNode := newWalkNode(root)
+	queue.push([]*walkNode{rootNode})
+
+	// a slot is taken for each directory read, and given back once its result is published
+	ahead := make(chan struct{}, jobs*WALK_AHEAD)
+	var workers sync.WaitGroup
+	for i := 0; i < jobs; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for {
+				select {
+				case ahead <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+				node := queue.pop()
+				if node == nil {
+					return
+				}
+				node.result, node.children = readWalkDir(node.dir, options)
+				for i, child := range node.children {
+					child.order = append(append(make([]int, 0, len(node.order)+1), node.order...), i)
+				}
+				queue.push(node.children)
+				close(node.done)
+				queue.finish()
+			}
+		}()
+	}
+	// wake idle workers if the walk is abandoned
+	go func() {
+		<-ctx.Done()
+		queue.close()
+	}()
+
+	ch := make(chan WalkResult)
+	go func() {
+		defer func() {
+			cancel()
+			workers.Wait()
+			close(ch)
+		}()
+		emitWalk(ctx, rootNode, ch, ahead)
+	}()
+	return ch
+}
+
+//-----------------------------------------
+// Private Utility Types & Functions
+//-----------------------------------------
+
+// walkNode is a directory of the walk. done is closed once result and children are set.
+// order holds the index of the directory among its siblings, and those of its ancestors, so
+// that nodes compare in the order they are published.
+type walkNode struct {
+	dir      string
+	order    []int
+	done     chan struct{}
+	result   WalkResult
+	children []*walkNode
+}
+
+func newWalkNode(dir string) *walkNode {
+	return &walkNode{dir: dir, done: make(chan struct{})}
+}
+
+// walkQueue holds the directories waiting to be read. The first to be published is popped
+// first, so that the directory the emitter waits on is never kept from being read by those
+// read ahead of it.
+type walkQueue struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	heap    walkHeap
+	pending int // directories pushed but not yet finished
+	closed  bool
+}
+
+func newWalkQueue() *walkQueue {
+	q := &walkQueue{}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// push queues nodes.
+func (q *walkQueue) push(nodes []*walkNode) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, node := range nodes {
+		heap.Push(&q.heap, node)
+	}
+	q.pending += len(nodes)
+	q.cond.Broadcast()
+}
+
+// pop returns the next directory to read, waiting for one if need be, or nil once the walk
+// is complete or the queue is closed.
+func (q *walkQueue) pop() *walkNode {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.heap) == 0 && q.pending > 0 && !q.closed {
+		q.cond.Wait()
+	}
+	if q.closed || len(q.heap) == 0 {
+		return nil
+	}
+	return heap.Pop(&q.heap).(*walkNode)
+}
+
+// finish records that a popped directory has been read.
+func (q *walkQueue) finish() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending--
+	if q.pending == 0 {
+		q.cond.Broadcast()
+	}
+}
+
+// close abandons the walk, waking any waiting workers.
+func (q *walkQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.cond.Broadcast()
+}
+
+// walkHeap orders nodes by the order in which they are published.
+type walkHeap []*walkNode
+
+func (h walkHeap) Len() int      { return len(h) }
+func (h walkHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h walkHeap) Less(i, j int) bool {
+	a, b := h[i].order, h[j].order
+	for k := 0; k < len(a) && k < len(b); k++ {
+		if a[k] != b[k] {
+			return a[k] < b[k]
+		}
+	}
+	return len(a) < len(b)
+}
+func (h *walkHeap) Push(x any) { *h = append(*h, x.(*walkNode)) }
+func (h *walkHeap) Pop() any {
+	old := *h
+	node := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return node
+}
+
+// emitWalk publishes the result of node, then those of its children, in order, giving back
+// the slot in ahead taken to read each, and dropping each result once published. It returns
+// false if ctx is cancelled first.
+func emitWalk(ctx context.Context, node *walkNode, ch chan WalkResult, ahead chan struct{}) bool {
+	select {
+	case <-node.done:
+	case <-ctx.Done():
+		return false
+	}
+	select {
+	case ch <- node.result:
+	case <-ctx.Done():
+		return false
+	}
+	children := node.children
+	node.result, node.children = WalkResult{}, nil
+	<-ahead
+	for _, child := range children {
+		if !emitWalk(ctx, child, ch, ahead) {
+			return false
+		}
+	}
+	return true
+}
+
+// readWalkDir collapses the entries of dir, returning the result along with the nodes of the
+// subdirectories to be walked, in natural order.
+func readWalkDir(dir string, options WalkOptions) (WalkResult, []*walkNode) {
+	result := WalkResult{Dir: dir, Sequences: []Sequence{}}
+	var filter, descend Filter
+	if options.Filter != nil {
+		if result.Err, filter = options.Filter(dir); result.Err != nil {
+			return result, nil
+		}
+	}
+	if options.Descend != nil {
+		if result.Err, descend = options.Descend(dir); result.Err != nil {
+			return result, nil
+		}
+	}
+
+	if options.Cache != nil {
+		err, entry := options.Cache.Collapse(dir)
+		if err != nil {
+			result.Err = err
+			return result, nil
+		}
+		result.Sequences = FilterSequences(entry.Sequences, filter)
+		subdirs := []string{}
+		for _, name := range entry.Subdirs {
+			if descend.Keep(name) {
+				subdirs = append(subdirs, name)
+			}
+		}
+		return result, newWalkNodes(dir, subdirs)
+	}
+
+	f, err := os.Open(dir)
+	if err != nil {
+		result.Err = err
+		return result, nil
+	}
+	defer f.Close()
+
+	debug := debugEnabled()
+	start := time.Now()
+	read := 0
+	builder := NewSequenceBuilder()
+	subdirs := []string{}
+	for {
+		entries, err := f.ReadDir(LISTING_BATCH)
+		read += len(entries)
+		for _, entry := range entries {
+			name := entry.Name()
+			if filter.Keep(name) {
+				builder.Add(name)
+			} else if debug {
+				logger.Debug("filtered out", "dir", dir, "name", name)
+			}
+			if entry.IsDir() && descend.Keep(name) {
+				subdirs = append(subdirs, name)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			result.Err = err
+			break
+		}
+	}
+	logger.Info("read directory", "path", dir, "entries", read, "subdirs", len(subdirs),
+		"elapsed", time.Since(start))
+	result.Sequences = builder.Sequences()
+	return result, newWalkNodes(dir, subdirs)
+}
+
+// newWalkNodes returns the nodes of the named subdirectories of dir, in natural order.
+func newWalkNodes(dir string, subdirs []string) []*walkNode {
+	sort.Slice(subdirs, func(i, j int) bool {
+		return NaturalLess(subdirs[i], subdirs[j])
+	})
+	children := make([]*walkNode, len(subdirs))
+	for i, name := range subdirs {
+		children[i] = newWalkNode(filepath.Join(dir, name))
+	}
+	return children
+}
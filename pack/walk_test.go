@@ -0,0 +1,173 @@
+package lss
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// makeTree creates a show like tree beneath a temporary directory, returning its root.
+func makeTree(t *testing.T) string {
+	root := t.TempDir()
+	for _, dir := range []string{
+		"shot10/render", "shot2/render", "shot2/comp", "shot1/.cache", "shot1/render/beauty",
+	} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+		for i := 1; i <= 3; i++ {
+			name := filepath.Join(root, dir, "img."+strconv.Itoa(1000+i)+".exr")
+			os.WriteFile(name, []byte{}, 0644)
+		}
+	}
+	os.Symlink(filepath.Join(root, "shot1"), filepath.Join(root, "link"))
+	return root
+}
+
+// walkDirs walks root, returning the directories visited relative to root, and the errors.
+func walkDirs(t *testing.T, root string, options WalkOptions) ([]string, []error) {
+	dirs, errs := []string{}, []error{}
+	for result := range Walk(context.Background(), root, options) {
+		rel, _ := filepath.Rel(root, result.Dir)
+		dirs = append(dirs, filepath.ToSlash(rel))
+		if result.Err != nil {
+			errs = append(errs, result.Err)
+		}
+	}
+	return dirs, errs
+}
+
+func TestWalk_Order(t *testing.T) {
+	root := makeTree(t)
+	expected := []string{
+		".", "shot1", "shot1/.cache", "shot1/render", "shot1/render/beauty",
+		"shot2", "shot2/comp", "shot2/render", "shot10", "shot10/render",
+	}
+	for _, jobs := range []int{0, 1, 4, 32} {
+		dirs, errs := walkDirs(t, root, WalkOptions{Jobs: jobs})
+		if len(errs) > 0 {
+			t.Error("walk failed:", errs)
+		}
+		if !testEq(dirs, expected) {
+			t.Error("walk with", jobs, "jobs visited", dirs, "Should Be:", expected)
+		}
+	}
+}
+
+func TestWalk_Results(t *testing.T) {
+	root := makeTree(t)
+	options := WalkOptions{
+		Jobs: 4,
+		Filter: func(dir string) (error, Filter) {
+			return nil, HiddenFilter(false)
+		},
+		Descend: func(dir string) (error, Filter) {
+			return nil, HiddenFilter(false)
+		},
+	}
+	for result := range Walk(context.Background(), root, options) {
+		err, listing := FilteredListingFromPath(result.Dir, HiddenFilter(false))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := SequencesFromStringSlice(listing); !reflect.DeepEqual(result.Sequences, want) {
+			t.Error(result.Dir, "collapsed to", result.Sequences, "Should Be:", want)
+		}
+		if filepath.Base(result.Dir) == "comp" && result.Sequences[0].String() != "img.%04d.exr 1001-1003" {
+			t.Error(result.Dir, "collapsed to", result.Sequences)
+		}
+		if strings.Contains(result.Dir, ".cache") {
+			t.Error("walked into hidden directory", result.Dir)
+		}
+	}
+}
+
+func TestWalk_ErrorsDoNotAbort(t *testing.T) {
+	root := makeTree(t)
+	denied := filepath.Join(root, "shot2")
+	options := WalkOptions{
+		Jobs: 4,
+		Filter: func(dir string) (error, Filter) {
+			if dir == denied {
+				return errors.New("permission denied"), nil
+			}
+			return nil, nil
+		},
+	}
+	dirs, errs := walkDirs(t, root, options)
+	if len(errs) != 1 {
+		t.Error("walk reported", errs, "Should Be: 1 error")
+	}
+	expected := []string{
+		".", "shot1", "shot1/.cache", "shot1/render", "shot1/render/beauty",
+		"shot2", "shot10", "shot10/render",
+	}
+	if !testEq(dirs, expected) {
+		t.Error("walk visited", dirs, "Should Be:", expected)
+	}
+}
+
+func TestWalk_PermissionDenied(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for root")
+	}
+	root := makeTree(t)
+	locked := filepath.Join(root, "shot2")
+	os.Chmod(locked, 0)
+	defer os.Chmod(locked, 0755)
+
+	dirs, errs := walkDirs(t, root, WalkOptions{Jobs: 4})
+	if len(errs) != 1 || !os.IsPermission(errs[0]) {
+		t.Error("walk reported", errs, "Should Be: permission denied")
+	}
+	if len(dirs) != 8 {
+		t.Error("walk visited", dirs)
+	}
+}
+
+func TestWalk_StopsOnCancel(t *testing.T) {
+	root := makeTree(t)
+	baseline := runtime.NumGoroutine()
+	for i := 0; i < 10; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		results := Walk(ctx, root, WalkOptions{Jobs: 4})
+		<-results
+		cancel()
+		for range results {
+		}
+	}
+	waitForGoroutines(t, baseline)
+}
+
+func TestWalk_BoundsReadAhead(t *testing.T) {
+	root := t.TempDir()
+	for i := 0; i < 50; i++ {
+		os.MkdirAll(filepath.Join(root, "shot"+strconv.Itoa(i), "render"), 0755)
+	}
+	var read atomic.Int32
+	options := WalkOptions{Jobs: 2, Filter: func(dir string) (error, Filter) {
+		read.Add(1)
+		return nil, nil
+	}}
+	results := Walk(context.Background(), root, options)
+	<-results
+	time.Sleep(50 * time.Millisecond)
+	if n := read.Load(); n > 2*WALK_AHEAD+1 {
+		t.Error("read", n, "directories ahead of a stalled reader. Should Be at most:", 2*WALK_AHEAD+1)
+	}
+	count := 1
+	for range results {
+		count++
+	}
+	if count != 101 {
+		t.Error("walked", count, "directories. Should Be: 101")
+	}
+}
@@ -0,0 +1,196 @@
+package lss
+
+/*
+watch provides the building blocks for watching a directory fill up over time. A Snapshot
+records the Sequences found in a directory at a point in time, DiffSnapshots reports what
+changed between two of them, and Progress estimates when each Sequence will be complete.
+*/
+
+import (
+	"sort"
+	"time"
+)
+
+//---------------------------
+// Type Snapshot
+//---------------------------
+
+// Snapshot records the Sequences in a directory at a point in time, keyed by pattern.
+type Snapshot struct {
+	Time      time.Time
+	Sequences map[string]Sequence
+}
+
+// NewSnapshot collapses the supplied directory contents and records them as of the
+// supplied time.
+func NewSnapshot(contents []string, at time.Time) *Snapshot {
+	return NewSnapshotFromSequences(SequencesFromStringSlice(contents), at)
+}
+
+// NewSnapshotFromSequences records already collapsed directory contents, listed at the time
+// at.
+func NewSnapshotFromSequences(seqs []Sequence, at time.Time) *Snapshot {
+	snap := &Snapshot{Time: at, Sequences: map[string]Sequence{}}
+	for _, seq := range seqs {
+		pattern := seq.Pattern()
+		if prev, ok := snap.Sequences[pattern]; ok && !seq.Single() {
+			seq.Frames = mergeFrames(prev.Frames, seq.Frames)
+		}
+		snap.Sequences[pattern] = seq
+	}
+	return snap
+}
+
+// Patterns returns the patterns of the Snapshot's Sequences, in listing order.
+func (s *Snapshot) Patterns() []string {
+	seqs := make([]Sequence, 0, len(s.Sequences))
+	for _, seq := range s.Sequences {
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool {
+		return sequenceLess(&seqs[i], &seqs[j])
+	})
+	patterns := make([]string, 0, len(seqs))
+	for _, seq := range seqs {
+		patterns = append(patterns, seq.Pattern())
+	}
+	return patterns
+}
+
+//---------------------------
+// Type SequenceChange
+//---------------------------
+
+// SequenceChange describes how a single Sequence changed between two Snapshots. Added and
+// Removed hold frame numbers; they are empty for single files, which can only appear or
+// disappear.
+type SequenceChange struct {
+	Pattern  string
+	Appeared bool
+	Vanished bool
+	Added    []int
+	Removed  []int
+}
+
+// DiffSnapshots reports the changes between two Snapshots of the same directory, in listing
+// order. A nil prev is treated as an empty Snapshot.
+func DiffSnapshots(prev *Snapshot, cur *Snapshot) []SequenceChange {
+	if prev == nil {
+		prev = &Snapshot{Sequences: map[string]Sequence{}}
+	}
+	changes := []SequenceChange{}
+
+	// walk the union of patterns, in listing order
+	union := &Snapshot{Sequences: map[string]Sequence{}}
+	for pattern, seq := range prev.Sequences {
+		union.Sequences[pattern] = seq
+	}
+	for pattern, seq := range cur.Sequences {
+		union.Sequences[pattern] = seq
+	}
+
+	for _, pattern := range union.Patterns() {
+		before, wasThere := prev.Sequences[pattern]
+		after, isThere := cur.Sequences[pattern]
+		change := SequenceChange{Pattern: pattern}
+		switch {
+		case !wasThere:
+			change.Appeared = true
+			change.Added = after.Frames
+		case !isThere:
+			change.Vanished = true
+			change.Removed = before.Frames
+		default:
+			change.Added = subtractFrames(after.Frames, before.Frames)
+			change.Removed = subtractFrames(before.Frames, after.Frames)
+			if len(change.Added) == 0 && len(change.Removed) == 0 {
+				continue
+			}
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+//---------------------------
+// Type Progress
+//---------------------------
+
+// Progress tracks the rate at which Sequences grow across Snapshots, in order to estimate
+// when each will reach the Expect-ed number of frames.
+type Progress struct {
+	Expect int
+	seen   map[string]progressMark
+}
+
+// progressMark records when a pattern was first seen, and how many frames it had then.
+type progressMark struct {
+	time  time.Time
+	count int
+}
+
+// NewProgress returns a Progress expecting each Sequence to end up with expect frames.
+func NewProgress(expect int) *Progress {
+	return &Progress{Expect: expect, seen: map[string]progressMark{}}
+}
+
+// Update records the first sighting of each Sequence in the Snapshot.
+func (p *Progress) Update(snap *Snapshot) {
+	for pattern, seq := range snap.Sequences {
+		if _, ok := p.seen[pattern]; !ok {
+			p.seen[pattern] = progressMark{snap.Time, seq.Len()}
+		}
+	}
+}
+
+// Estimate returns the estimated completion time of the Sequence with the supplied pattern,
+// based upon its average rate of growth since it was first seen. The boolean is false if no
+// estimate can be made yet (eg no frames have landed since the Sequence was first seen).
+// Sequences which are already complete report the time of the Snapshot.
+func (p *Progress) Estimate(snap *Snapshot, pattern string) (bool, time.Time) {
+	seq, ok := snap.Sequences[pattern]
+	mark, seen := p.seen[pattern]
+	if !ok || !seen || seq.Single() || p.Expect <= 0 {
+		return false, time.Time{}
+	}
+	count := seq.Len()
+	if count >= p.Expect {
+		return true, snap.Time
+	}
+	elapsed := snap.Time.Sub(mark.time)
+	if count <= mark.count || elapsed <= 0 {
+		return false, time.Time{}
+	}
+	perFrame := elapsed / time.Duration(count-mark.count)
+	return true, snap.Time.Add(perFrame * time.Duration(p.Expect-count))
+}
+
+//-----------------------------------------
+// Private Utility Functions
+//-----------------------------------------
+
+// subtractFrames returns the members of the ascending slice lhs absent from the ascending
+// slice rhs.
+func subtractFrames(lhs []int, rhs []int) []int {
+	ret := []int{}
+	j := 0
+	for _, frame := range lhs {
+		for j < len(rhs) && rhs[j] < frame {
+			j++
+		}
+		if j < len(rhs) && rhs[j] == frame {
+			continue
+		}
+		ret = append(ret, frame)
+	}
+	return ret
+}
+
+// mergeFrames returns the sorted union of two ascending slices of frames.
+func mergeFrames(lhs []int, rhs []int) []int {
+	ret := make([]int, 0, len(lhs)+len(rhs))
+	ret = append(ret, lhs...)
+	ret = append(ret, subtractFrames(rhs, lhs)...)
+	sort.Ints(ret)
+	return ret
+}
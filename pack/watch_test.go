@@ -0,0 +1,64 @@
+package lss
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWatch_DiffSnapshots(t *testing.T) {
+	start := time.Date(2015, 1, 1, 12, 0, 0, 0, time.UTC)
+	prev := NewSnapshot([]string{
+		"foo.0001.exr",
+		"foo.0002.exr",
+		"foo.0003.exr",
+		"old.txt",
+	}, start)
+	cur := NewSnapshot([]string{
+		"foo.0001.exr",
+		"foo.0003.exr",
+		"foo.0004.exr",
+		"foo.0005.exr",
+		"bar.0001.dpx",
+	}, start.Add(time.Minute))
+
+	changes := DiffSnapshots(prev, cur)
+	if len(changes) != 3 {
+		t.Fatal("Wrong number of changes:", len(changes), changes)
+	}
+
+	bar, foo, old := changes[0], changes[1], changes[2]
+	if bar.Pattern != "bar.%04d.dpx" || !bar.Appeared || FrameRangeString(bar.Added) != "1" {
+		t.Error("new sequence not reported:", bar)
+	}
+	if foo.Pattern != "foo.%04d.exr" || foo.Appeared || foo.Vanished ||
+		FrameRangeString(foo.Added) != "4-5" || FrameRangeString(foo.Removed) != "2" {
+		t.Error("changed sequence not reported:", foo)
+	}
+	if old.Pattern != "old.txt" || !old.Vanished {
+		t.Error("vanished file not reported:", old)
+	}
+
+	if len(DiffSnapshots(cur, cur)) != 0 {
+		t.Error("identical snapshots reported changes")
+	}
+}
+
+func TestWatch_ProgressEstimate(t *testing.T) {
+	start := time.Date(2015, 1, 1, 12, 0, 0, 0, time.UTC)
+	progress := NewProgress(10)
+
+	first := NewSnapshot([]string{"foo.0001.exr", "foo.0002.exr"}, start)
+	progress.Update(first)
+	if ok, _ := progress.Estimate(first, "foo.%04d.exr"); ok {
+		t.Error("Estimate should not be possible from a single snapshot")
+	}
+
+	// two more frames a minute later: 6 frames to go at 30s per frame
+	second := NewSnapshot([]string{"foo.0001.exr", "foo.0002.exr", "foo.0003.exr", "foo.0004.exr"},
+		start.Add(time.Minute))
+	progress.Update(second)
+	ok, eta := progress.Estimate(second, "foo.%04d.exr")
+	if !ok || !eta.Equal(start.Add(4*time.Minute)) {
+		t.Error("Wrong estimate:", ok, eta)
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"github.com/codegangsta/cli"
+	"github.com/jlgerber/lss/pack"
+	"os"
+	"path/filepath"
+)
+
+var convertCommand = cli.Command{
+	Name:  "convert",
+	Usage: "rewrite a sequence pattern in another notation.",
+	Description: `Print Pattern, written in any notation, in the notation given by --to:
+
+	printf (or nuke)  foo.%04d.exr
+	hash (or maya)    foo.####.exr
+	at                foo.@@@@.exr
+	houdini           foo.$F4.exr
+	rv                foo.1-100#.exr (or foo.@@@@.exr, without a range)
+
+	lss convert --to houdini|nuke|hash|rv <Pattern>
+
+	A range of frames, for rv, may be given with --frames, or is taken from a Pattern in rv
+	notation.`,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "to",
+			Usage: "the notation to write: printf (or nuke), hash (or maya), at, houdini or rv.",
+		},
+		cli.StringFlag{
+			Name:  "frames",
+			Usage: "the range of frames of the sequence, for rv (eg 1001-1100).",
+		},
+	},
+	Action: func(c *cli.Context) {
+		if len(c.Args()) != 1 {
+			fail("convert needs a single pattern (eg lss convert --to houdini foo.%04d.exr)")
+		}
+		if c.String("to") == "" {
+			fail("convert needs a notation to convert to (eg --to houdini)")
+		}
+		err, notation := lss.ParseNotation(c.String("to"))
+		if err != nil {
+			fail(err)
+		}
+		err, seq := lss.ParsePattern(c.Args().First())
+		if err != nil {
+			fail(err)
+		}
+		if c.String("frames") != "" {
+			if err, seq.Frames = lss.ParseFrameRange(c.String("frames")); err != nil {
+				fail(err)
+			}
+		}
+		fmt.Println(lss.Style{Notation: notation}.Pattern(&seq))
+	},
+}
+
+var genCommand = cli.Command{
+	Name:  "gen",
+	Usage: "print the names of a sequence pattern's frames, without reading the disk.",
+	Description: `Print the name of each of Frames of the sequence denoted by Pattern, whether or not
+	the files exist, eg for render job manifests. The pattern may be written in any notation
+	(foo.%04d.exr, foo.####.exr, foo.@@@@.exr or foo.$F4.exr), and the frames in range form,
+	with an optional step (eg 1-100x2). This is the inverse of listing: lss lists the names
+	as Pattern Frames.
+
+	lss gen <Pattern> <Frames>`,
+	Action: func(c *cli.Context) {
+		if len(c.Args()) != 2 {
+			fail("gen needs a pattern and frames (eg lss gen foo.%04d.exr 1-100x2)")
+		}
+		err, frames := lss.ParseFrameRange(c.Args().Get(1))
+		if err != nil {
+			fail(err)
+		}
+		err, names := lss.GenerateNames(c.Args().First(), frames)
+		if err != nil {
+			fail(err)
+		}
+		for _, name := range names {
+			fmt.Println(name)
+		}
+	},
+}
+
+var expandCommand = cli.Command{
+	Name:  "expand",
+	Usage: "print the files of a sequence pattern which exist.",
+	Description: `Print the full path of each existing member of the sequence denoted by Pattern, in
+	frame order. The pattern may be written in any notation (foo.%04d.exr, foo.####.exr,
+	foo.@@@@.exr or foo.$F4.exr). With --frames, or a range in rv notation (foo.1-100#.exr),
+	only those frames are printed, and any which do not exist are reported, in range form,
+	with an exit status of 1.
+
+	lss expand [--frames 1-10] <Pattern>`,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "frames",
+			Usage: "only expand these frames (eg 1-10, 1-3,5 or 1-100x2).",
+		},
+	},
+	Action: func(c *cli.Context) {
+		if len(c.Args()) != 1 {
+			fail("expand needs a single pattern (eg foo.%04d.exr)")
+		}
+		pattern, err := filepath.Abs(c.Args().First())
+		if err != nil {
+			fail(err)
+		}
+		var frames []int
+		if c.String("frames") != "" {
+			if err, frames = lss.ParseFrameRange(c.String("frames")); err != nil {
+				fail(err)
+			}
+		}
+
+		err, found, missing := lss.ExpandPattern(pattern, frames)
+		if err != nil {
+			fail(err)
+		}
+		for _, path := range found.Names() {
+			fmt.Println(path)
+		}
+		switch {
+		case len(missing) > 0:
+			fmt.Fprintln(os.Stderr, "lss: missing frames", lss.FrameRangeString(missing), "of", c.Args().First())
+			exit(EXIT_PROBLEMS)
+		case found.Len() == 0:
+			fmt.Fprintln(os.Stderr, "lss: no files match", c.Args().First())
+			exit(EXIT_PROBLEMS)
+		}
+	},
+}
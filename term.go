@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"github.com/codegangsta/cli"
+	"github.com/jlgerber/lss/pack"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// DEFAULT_WIDTH is the width assumed when that of the terminal cannot be determined.
+const DEFAULT_WIDTH = 80
+
+// isTerminal returns true if f is a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// terminalWidth returns the width of the terminal in cells, as given by $COLUMNS, or by the
+// terminal attached to stdout, or DEFAULT_WIDTH.
+func terminalWidth() int {
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	if width := terminalSize(os.Stdout); width > 0 {
+		return width
+	}
+	return DEFAULT_WIDTH
+}
+
+// useColor decides whether --color (auto, always or never) calls for colored output. auto
+// colors only when stdout is a terminal, and $NO_COLOR is unset, and $TERM is not dumb.
+func useColor(c *cli.Context) (error, bool) {
+	switch when := settingString(c, "color"); when {
+	case "always":
+		return nil, true
+	case "never":
+		return nil, false
+	case "auto":
+		return nil, isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb"
+	default:
+		return errors.New("unknown --color:'" + when + "' (use auto, always or never)"), false
+	}
+}
+
+// outputFormatter returns the Formatter for listing the directory dir ("" if the names
+// carry their own directories) in style, colored as requested by --color and the colors
+// setting (usually $LSS_COLORS), and with timecodes at the rate given by --fps.
+func outputFormatter(c *cli.Context, dir string, style lss.Style) (error, *lss.Formatter) {
+	err, rate := settingRate(c)
+	if err != nil {
+		return err, nil
+	}
+	format := &lss.Formatter{Style: style, Rate: rate}
+	err, color := useColor(c)
+	if err != nil || !color {
+		return err, format
+	}
+	err, colors := lss.ParseColors(settings.Defaults["colors"])
+	if err != nil {
+		return errors.New("colors: " + err.Error()), nil
+	}
+	format.Colors = colors
+	format.IsDir = func(name string) bool {
+		info, err := os.Stat(filepath.Join(dir, name))
+		return err == nil && info.IsDir()
+	}
+	return nil, format
+}
+
+// settingRate returns the frame rate given by --fps, or the zero Rate if there is none.
+func settingRate(c *cli.Context) (error, lss.Rate) {
+	fps := settingString(c, "fps")
+	if fps == "" {
+		return nil, lss.Rate{}
+	}
+	return lss.ParseRate(fps)
+}
+
+// formatSequences renders seqs with f, in columns with --columns.
+func formatSequences(c *cli.Context, f *lss.Formatter, seqs []lss.Sequence) []string {
+	if settingBool(c, "columns") {
+		return f.Columns(seqs, terminalWidth())
+	}
+	return f.Lines(seqs)
+}
@@ -0,0 +1,10 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package main
+
+import "os"
+
+// terminalSize returns 0, as the terminal size cannot be queried on this platform.
+func terminalSize(f *os.File) int {
+	return 0
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"github.com/jlgerber/lss/pack"
+	"time"
+)
+
+// clearScreen moves the cursor home and clears the terminal.
+const clearScreen = "\033[H\033[2J"
+
+// watchPath re-lists path every interval, redrawing the collapsed listing along with the
+// changes since the previous tick. When expect is positive, each sequence is annotated with
+// its estimated completion time. Lines are rendered by format, and ambiguous frames are
+// placed according to policy. Polling is used, rather than filesystem notifications, so that
+// it works over NFS.
+func watchPath(path string, filter func(string) bool, policy lss.PaddingPolicy, cache *lss.Cache, format *lss.Formatter, interval time.Duration, expect int) {
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	progress := lss.NewProgress(expect)
+	var prev *lss.Snapshot
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		err, seqs := lss.CachedCollapsePath(cache, path, filter)
+		if err == nil && policy != lss.POLICY_AUTO {
+			seqs, _ = lss.RegroupSequences(seqs, nil, policy)
+		}
+		now := time.Now()
+		fmt.Print(clearScreen)
+		fmt.Printf("Every %s: lss %s    %s\n\n", interval, path, now.Format("2006-01-02 15:04:05"))
+
+		if err != nil {
+			fmt.Println(err)
+		} else {
+			snap := lss.NewSnapshotFromSequences(seqs, now)
+			progress.Update(snap)
+			printWatchTick(format, snap, prev, progress)
+			prev = snap
+		}
+		<-ticker.C
+	}
+}
+
+// printWatchTick prints the listing of snap using format, annotated with the frames added
+// since prev and the estimated completion of each sequence, followed by a summary of the
+// changes.
+func printWatchTick(format *lss.Formatter, snap *lss.Snapshot, prev *lss.Snapshot, progress *lss.Progress) {
+	patterns := snap.Patterns()
+	changes := lss.DiffSnapshots(prev, snap)
+	added := map[string]int{}
+	for _, change := range changes {
+		added[change.Pattern] = len(change.Added)
+	}
+
+	seqs := make([]lss.Sequence, len(patterns))
+	for i, pattern := range patterns {
+		seqs[i] = snap.Sequences[pattern]
+	}
+	lines := format.Lines(seqs)
+
+	for i, pattern := range patterns {
+		seq := seqs[i]
+		line := lines[i]
+		if prev != nil && added[pattern] > 0 {
+			line += fmt.Sprintf("    [+%d]", added[pattern])
+		}
+		if progress.Expect > 0 && !seq.Single() {
+			if ok, eta := progress.Estimate(snap, pattern); !ok {
+				line += fmt.Sprintf("    %d/%d ETA unknown", seq.Len(), progress.Expect)
+			} else if seq.Len() >= progress.Expect {
+				line += fmt.Sprintf("    %d/%d done", seq.Len(), progress.Expect)
+			} else {
+				line += fmt.Sprintf("    %d/%d ETA %s", seq.Len(), progress.Expect,
+					eta.Format("15:04:05"))
+			}
+		}
+		fmt.Println(line)
+	}
+
+	// on the first tick everything is new, so there is nothing worth reporting
+	if prev == nil || len(changes) == 0 {
+		return
+	}
+	fmt.Println("\nChanges:")
+	for _, change := range changes {
+		switch {
+		case change.Appeared:
+			fmt.Println("  NEW ", change.Pattern, lss.FrameRangeString(change.Added))
+		case change.Vanished:
+			fmt.Println("  GONE", change.Pattern, lss.FrameRangeString(change.Removed))
+		default:
+			if len(change.Added) > 0 {
+				fmt.Println("  +   ", change.Pattern, lss.FrameRangeString(change.Added))
+			}
+			if len(change.Removed) > 0 {
+				fmt.Println("  -   ", change.Pattern, lss.FrameRangeString(change.Removed))
+			}
+		}
+	}
+}